@@ -0,0 +1,158 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "github.com/mpfen/Go-Todo-REST-API/api"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Creates an API key with the given scopes using the login token and returns the plaintext key
+func createTestAPIKey(t *testing.T, server *api.TodoStore, token string, scopes ...model.Scope) string {
+	t.Helper()
+
+	request := newRequestWithToken(http.MethodPost, "/api-keys", token, makeNewAPIKeyBody(t, "ci", scopes, time.Time{}))
+	response := httptest.NewRecorder()
+
+	server.Router.ServeHTTP(response, request)
+	assertResponseStatus(t, response.Code, http.StatusCreated)
+
+	var body map[string]interface{}
+	json.NewDecoder(response.Body).Decode(&body)
+
+	key, _ := body["key"].(string)
+	return key
+}
+
+// Tests for routes /api-keys and API key authentication
+func TestAPIKeys(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	token := createTestUser(t, db, "alice")
+
+	request := newRequestWithToken(http.MethodPost, "/projects", token, makeNewPostProjectBody(t, "homework"))
+	server.Router.ServeHTTP(httptest.NewRecorder(), request)
+
+	readKey := createTestAPIKey(t, server, token, model.ScopeProjectsRead, model.ScopeTasksRead)
+
+	cases := []struct {
+		name   string
+		method string
+		url    string
+		want   int
+	}{
+		{"read key can list projects", http.MethodGet, "/projects", http.StatusOK},
+		{"read key can not archive projects", http.MethodPut, "/projects/homework/archive", http.StatusForbidden},
+		{"read key can not create tasks", http.MethodPost, "/projects/homework/tasks", http.StatusForbidden},
+		{"read key can not manage api keys", http.MethodGet, "/api-keys", http.StatusForbidden},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			request := newRequestWithToken(c.method, c.url, readKey, bytes.NewBufferString(`{"name": "math"}`))
+			response := httptest.NewRecorder()
+
+			server.Router.ServeHTTP(response, request)
+
+			assertResponseStatus(t, response.Code, c.want)
+		})
+	}
+
+	t.Run("Write scope allows creating tasks", func(t *testing.T) {
+		writeKey := createTestAPIKey(t, server, token, model.ScopeTasksWrite)
+
+		request := newRequestWithToken(http.MethodPost, "/projects/homework/tasks", writeKey, makeNewPostTaskBody(t, "math", "homework"))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+	})
+
+	t.Run("Usage is recorded and the key is listed", func(t *testing.T) {
+		request := newRequestWithToken(http.MethodGet, "/api-keys", token, nil)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		var keys []model.APIKey
+		json.NewDecoder(response.Body).Decode(&keys)
+
+		if len(keys) != 2 {
+			t.Fatalf("got %d keys, want 2", len(keys))
+		}
+		if keys[0].LastUsedAt == nil {
+			t.Errorf("last_used_at was not set")
+		}
+	})
+
+	t.Run("Unknown scopes are rejected", func(t *testing.T) {
+		request := newRequestWithToken(http.MethodPost, "/api-keys", token, makeNewAPIKeyBody(t, "ci", []model.Scope{"tasks:delete"}, time.Time{}))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("API keys can not log out", func(t *testing.T) {
+		adminKey := createTestAPIKey(t, server, token, model.ScopeAdmin)
+
+		request := newRequestWithToken(http.MethodPost, "/logout", adminKey, nil)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Revoked keys are rejected", func(t *testing.T) {
+		key := db.GetAPIKey(model.HashToken(readKey))
+
+		request := newRequestWithToken(http.MethodDelete, fmt.Sprintf("/api-keys/%d", key.ID), token, nil)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		request = newRequestWithToken(http.MethodGet, "/projects", readKey, nil)
+		response = httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Expired keys are rejected", func(t *testing.T) {
+		key, secret, _ := model.NewAPIKey(db.GetUser("alice").ID, "old", model.Scopes{model.ScopeAdmin}, time.Now().Add(-time.Hour))
+		db.PostAPIKey(key)
+
+		request := newRequestWithToken(http.MethodGet, "/projects", secret, nil)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusUnauthorized)
+	})
+}
+
+// makes a new json request body for POST /api-keys
+func makeNewAPIKeyBody(t *testing.T, name string, scopes []model.Scope, expiresAt time.Time) *bytes.Buffer {
+	body := map[string]interface{}{
+		"name":   name,
+		"scopes": scopes,
+	}
+	if !expiresAt.IsZero() {
+		body["expires_at"] = expiresAt
+	}
+
+	requestBody, err := json.Marshal(body)
+
+	if err != nil {
+		t.Errorf("Failed to make requestBody: %s", err)
+	}
+
+	return bytes.NewBuffer(requestBody)
+}
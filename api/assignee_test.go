@@ -0,0 +1,107 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for assigning tasks and listing the tasks of the current user
+func TestAssignees(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+	createTestUser(t, db, "carol")
+
+	getTasks := func(token, url string) []model.Task {
+		var tasks []model.Task
+		json.NewDecoder(serveWithToken(server, token, http.MethodGet, url, "").Body).Decode(&tasks)
+		return tasks
+	}
+
+	names := func(tasks []model.Task) []string {
+		got := []string{}
+		for _, task := range tasks {
+			got = append(got, task.Name)
+		}
+		return got
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "home"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/home/members", `{"user": "bob", "role": "editor"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks", `{"name": "dishes", "deadline": "2030-01-03T18:00:00Z"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks", `{"name": "laundry", "deadline": "2030-01-02T18:00:00Z"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks", `{"name": "garden"}`)
+
+	serveWithToken(server, bob, http.MethodPost, "/projects", `{"name": "work"}`)
+	serveWithToken(server, bob, http.MethodPost, "/projects/work/tasks", `{"name": "report", "deadline": "2030-01-01T09:00:00Z"}`)
+
+	t.Run("Assign members of the project", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks/dishes/assignees", `{"user": "bob"}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks/dishes/assignees", `{"user": "alice"}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks/dishes/assignees", `{"user": "bob"}`)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks/dishes/assignees", `{"user": "carol"}`)
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+
+		serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks/laundry/assignees", `{"user": "bob"}`)
+		serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks/garden/assignees", `{"user": "bob"}`)
+		serveWithToken(server, bob, http.MethodPost, "/projects/work/tasks/report/assignees", `{"user": "bob"}`)
+	})
+
+	t.Run("List my open tasks by deadline", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPut, "/projects/home/tasks/garden/complete", "")
+
+		got := names(getTasks(bob, "/me/tasks"))
+		if len(got) != 3 || got[0] != "report" || got[1] != "laundry" || got[2] != "dishes" {
+			t.Errorf("got tasks %v, want report, laundry and dishes", got)
+		}
+	})
+
+	t.Run("Filter project tasks by assignee", func(t *testing.T) {
+		got := names(getTasks(alice, "/projects/home/tasks?assignee=alice"))
+		if len(got) != 1 || got[0] != "dishes" {
+			t.Errorf("got tasks %v, want dishes", got)
+		}
+
+		serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks", `{"name": "windows"}`)
+
+		got = names(getTasks(alice, "/projects/home/tasks?assignee=unassigned"))
+		if len(got) != 1 || got[0] != "windows" {
+			t.Errorf("got tasks %v, want windows", got)
+		}
+
+		got = names(getTasks(alice, "/projects/home/tasks?assignee=alice,unassigned"))
+		if len(got) != 2 || got[0] != "dishes" || got[1] != "windows" {
+			t.Errorf("got tasks %v, want dishes and windows", got)
+		}
+	})
+
+	t.Run("Unassign users", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodDelete, "/projects/home/tasks/dishes/assignees/bob", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodDelete, "/projects/home/tasks/dishes/assignees/bob", "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+
+		if got := names(getTasks(bob, "/me/tasks")); len(got) != 2 {
+			t.Errorf("got tasks %v, want report and laundry", got)
+		}
+	})
+
+	t.Run("Tasks of projects the user left are not listed", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodDelete, "/projects/home/members/bob", "")
+
+		got := names(getTasks(bob, "/me/tasks"))
+		if len(got) != 1 || got[0] != "report" {
+			t.Errorf("got tasks %v, want report", got)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/blob"
+	"github.com/mpfen/Go-Todo-REST-API/api/handler"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for uploading, downloading and deleting attachments of tasks
+func TestAttachments(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	dir := t.TempDir()
+	files, err := blob.NewStore(dir)
+	assertError(t, "create blob store", err)
+
+	server.Attachments = handler.NewAttachmentStorage(files)
+	server.Attachments.MaxFileSize = 16
+	server.Attachments.MaxProjectSize = 48
+
+	upload := func(url, fileName, content string) *httptest.ResponseRecorder {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", fileName)
+		part.Write([]byte(content))
+		writer.Close()
+
+		request := newRequestWithToken(http.MethodPost, url, alice, body)
+		request.Header.Set("content-type", writer.FormDataContentType())
+		return serveRequest(server, request)
+	}
+
+	getAttachments := func(url string) []model.Attachment {
+		var attachments []model.Attachment
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, url, "").Body).Decode(&attachments)
+		return attachments
+	}
+
+	stored := func(hash string) bool {
+		_, err := os.Stat(filepath.Join(dir, hash[:2], hash))
+		return err == nil
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "launch"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/launch/tasks", `{"name": "press kit"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/launch/tasks", `{"name": "slides"}`)
+
+	attachments := "/projects/launch/tasks/press kit/attachments"
+
+	t.Run("Upload and list files", func(t *testing.T) {
+		response := upload(attachments, "logo.png", "not really a png")
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = upload(attachments, "../../notes.txt", "hello")
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		got := getAttachments(attachments)
+		if len(got) != 2 || got[0].FileName != "logo.png" || got[0].ContentType != "image/png" || got[1].FileName != "notes.txt" || got[1].Size != 5 {
+			t.Fatalf("got attachments %v, want logo.png and notes.txt", got)
+		}
+		if !stored(got[0].Hash) {
+			t.Error("file was not stored under its hash")
+		}
+	})
+
+	t.Run("Download a file", func(t *testing.T) {
+		notes := getAttachments(attachments)[1]
+
+		response := serveWithToken(server, alice, http.MethodGet, fmt.Sprintf("%v/%d", attachments, notes.ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		assertResponseBody(t, response.Body.String(), "hello")
+		if contentType := response.Header().Get("content-type"); !strings.HasPrefix(contentType, "text/plain") {
+			t.Errorf("got content type %q, want text/plain", contentType)
+		}
+		assertResponseBody(t, response.Header().Get("content-disposition"), `attachment; filename=notes.txt`)
+	})
+
+	t.Run("Files larger than the limit are rejected", func(t *testing.T) {
+		response := upload(attachments, "big.txt", strings.Repeat("a", 17))
+		assertResponseStatus(t, response.Code, http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("Projects have a size limit", func(t *testing.T) {
+		response := upload("/projects/launch/tasks/slides/attachments", "a.txt", strings.Repeat("b", 16))
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = upload("/projects/launch/tasks/slides/attachments", "b.txt", strings.Repeat("c", 16))
+		assertResponseStatus(t, response.Code, http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("Uploads need a file", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, attachments, `{"file": "logo.png"}`)
+		assertResponseStatus(t, response.Code, http.StatusUnsupportedMediaType)
+	})
+
+	t.Run("Files shared by attachments are kept until the last one is deleted", func(t *testing.T) {
+		response := upload("/projects/launch/tasks/slides/attachments", "copy.txt", "hello")
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+		notes := getAttachments(attachments)[1]
+
+		response = serveWithToken(server, alice, http.MethodDelete, fmt.Sprintf("%v/%d", attachments, notes.ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if !stored(notes.Hash) {
+			t.Error("file of the copy was deleted")
+		}
+	})
+
+	t.Run("Purging a task deletes its files", func(t *testing.T) {
+		logo := getAttachments(attachments)[0]
+
+		response := serveWithToken(server, alice, http.MethodDelete, "/projects/launch/tasks/press kit", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if !stored(logo.Hash) {
+			t.Error("file of the task in the trash was deleted")
+		}
+
+		response = serveWithToken(server, alice, http.MethodDelete, fmt.Sprintf("/trash/tasks/%d", logo.TaskID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if stored(logo.Hash) {
+			t.Error("file of the purged task was not deleted")
+		}
+	})
+
+	t.Run("Purging a project deletes its files", func(t *testing.T) {
+		slides := getAttachments("/projects/launch/tasks/slides/attachments")
+
+		response := serveWithToken(server, alice, http.MethodDelete, "/projects/launch", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodDelete, fmt.Sprintf("/trash/projects/%d", slides[0].ProjectID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		for _, attachment := range slides {
+			if stored(attachment.Hash) {
+				t.Errorf("file %v of the purged project was not deleted", attachment.FileName)
+			}
+		}
+	})
+}
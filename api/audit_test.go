@@ -0,0 +1,124 @@
+package api_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for route GET /audit
+func TestAuditLog(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+
+	requests := []struct {
+		method string
+		url    string
+		body   io.Reader
+	}{
+		{http.MethodPost, "/projects", makeNewPostProjectBody(t, "homework")},
+		{http.MethodPost, "/projects/homework/tasks", makeNewPostTaskBody(t, "math", "homework")},
+		{http.MethodPut, "/projects/homework/tasks/math/complete", nil},
+		{http.MethodDelete, "/projects/homework/tasks/math", nil},
+		{http.MethodPut, "/projects/homework", makeNewPostProjectBody(t, "schoolwork")},
+	}
+
+	for _, req := range requests {
+		request := newRequestWithToken(req.method, req.url, alice, req.body)
+		response := serveRequest(server, request)
+
+		if response.Code >= 300 {
+			t.Fatalf("%v %v failed with %d", req.method, req.url, response.Code)
+		}
+	}
+
+	getAudit := func(token string, query url.Values) (*httptest.ResponseRecorder, []model.AuditEntry) {
+		request := newRequestWithToken(http.MethodGet, "/audit?"+query.Encode(), token, nil)
+		response := serveRequest(server, request)
+
+		var entries []model.AuditEntry
+		json.NewDecoder(response.Body).Decode(&entries)
+		return response, entries
+	}
+
+	t.Run("Every change is recorded, newest first", func(t *testing.T) {
+		response, entries := getAudit(alice, url.Values{})
+
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		want := []string{model.AuditRename, model.AuditDelete, model.AuditComplete, model.AuditCreate, model.AuditCreate}
+		if len(entries) != len(want) {
+			t.Fatalf("got %d entries, want %d", len(entries), len(want))
+		}
+		for i, entry := range entries {
+			assertResponseBody(t, entry.Action, want[i])
+			assertResponseBody(t, entry.Actor, "alice")
+		}
+	})
+
+	t.Run("Deleted tasks keep their last state", func(t *testing.T) {
+		_, entries := getAudit(alice, url.Values{"resource": {model.ResourceTask}})
+
+		if len(entries) != 3 {
+			t.Fatalf("got %d task entries, want 3", len(entries))
+		}
+
+		deleted := entries[0]
+		var before model.Task
+		json.Unmarshal(deleted.Before, &before)
+
+		assertResponseBody(t, before.Name, "math")
+		if !before.Done || string(deleted.After) != "null" {
+			t.Errorf("got before %s and after %s", deleted.Before, deleted.After)
+		}
+	})
+
+	t.Run("Filter by time range", func(t *testing.T) {
+		_, entries := getAudit(alice, url.Values{"from": {time.Now().Add(time.Hour).Format(time.RFC3339)}})
+
+		if len(entries) != 0 {
+			t.Errorf("got %d entries, want none", len(entries))
+		}
+
+		_, entries = getAudit(alice, url.Values{"from": {time.Now().Add(-time.Hour).Format(time.RFC3339)}, "to": {time.Now().Add(time.Hour).Format(time.RFC3339)}})
+
+		if len(entries) != 5 {
+			t.Errorf("got %d entries, want 5", len(entries))
+		}
+	})
+
+	t.Run("Filter by actor", func(t *testing.T) {
+		_, entries := getAudit(alice, url.Values{"actor": {"bob"}})
+
+		if len(entries) != 0 {
+			t.Errorf("got %d entries, want none", len(entries))
+		}
+	})
+
+	t.Run("Entries of other projects are hidden", func(t *testing.T) {
+		_, entries := getAudit(bob, url.Values{})
+
+		if len(entries) != 0 {
+			t.Errorf("got %d entries, want none", len(entries))
+		}
+	})
+
+	t.Run("Invalid time filter", func(t *testing.T) {
+		response, _ := getAudit(alice, url.Values{"from": {"yesterday"}})
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Non-positive limit", func(t *testing.T) {
+		response, _ := getAudit(alice, url.Values{"limit": {"0"}})
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,120 @@
+// Package blob stores files on the local disk under the SHA-256 hash of their content.
+// Files with the same content are stored once.
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var (
+	ErrTooLarge    = errors.New("file is too large")
+	ErrInvalidHash = errors.New("hash is not a hex encoded SHA-256 hash")
+)
+
+// Stores files in a directory. The files are named after their hash
+// and sharded into subdirectories by the first two characters
+type Store struct {
+	Dir string
+}
+
+// Returns a store for the directory, which is created if it does not exist
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &Store{Dir: dir}, nil
+}
+
+// Content written to the store that is not yet stored under its hash
+type Upload struct {
+	Hash string
+	Size int64
+
+	store *Store
+	temp  string
+}
+
+// Writes the content of the reader to a temporary file and returns it with its hash and size.
+// Returns ErrTooLarge and keeps nothing if the content is longer than limit bytes.
+// The upload has to be committed to be stored and discarded in any case
+func (s *Store) Upload(r io.Reader, limit int64) (*Upload, error) {
+	temp, err := ioutil.TempFile(s.Dir, "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer temp.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(temp, hash), io.LimitReader(r, limit+1))
+	if err == nil && size > limit {
+		err = ErrTooLarge
+	}
+	if err == nil {
+		err = temp.Close()
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return nil, err
+	}
+
+	return &Upload{Hash: hex.EncodeToString(hash.Sum(nil)), Size: size, store: s, temp: temp.Name()}, nil
+}
+
+// Stores the content under its hash. Nothing is written if the same content is already stored
+func (u *Upload) Commit() error {
+	path := u.store.path(u.Hash)
+
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	return os.Rename(u.temp, path)
+}
+
+// Removes the temporary file, a committed upload stays stored
+func (u *Upload) Discard() {
+	os.Remove(u.temp)
+}
+
+// Opens the file with the hash
+func (s *Store) Open(hash string) (*os.File, error) {
+	if !validHash(hash) {
+		return nil, ErrInvalidHash
+	}
+	return os.Open(s.path(hash))
+}
+
+// Deletes the file with the hash. Deleting a missing file is no error
+func (s *Store) Delete(hash string) error {
+	if !validHash(hash) {
+		return ErrInvalidHash
+	}
+
+	err := os.Remove(s.path(hash))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (s *Store) path(hash string) string {
+	return filepath.Join(s.Dir, hash[:2], hash)
+}
+
+// Hashes are used in paths, they must not contain anything else than hex digits
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil
+}
@@ -0,0 +1,121 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for the checklist routes of tasks
+func TestChecklists(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	getTask := func(url string) model.Task {
+		var task model.Task
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, url, "").Body).Decode(&task)
+		return task
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "trip"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/trip/tasks", `{"name": "packing"}`)
+
+	task := "/projects/trip/tasks/packing"
+	checklist := task + "/checklist"
+
+	t.Run("Add items to the end of the checklist", func(t *testing.T) {
+		for _, text := range []string{"passport", "charger", "tickets"} {
+			response := serveWithToken(server, alice, http.MethodPost, checklist, fmt.Sprintf(`{"text": %q}`, text))
+			assertResponseStatus(t, response.Code, http.StatusCreated)
+		}
+
+		response := serveWithToken(server, alice, http.MethodPost, checklist, `{"text": " "}`)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		got := getTask(task).Checklist
+		if len(got) != 3 || got[0].Text != "passport" || got[2].Text != "tickets" {
+			t.Errorf("got checklist %v, want passport, charger and tickets", got)
+		}
+	})
+
+	t.Run("Check and uncheck items", func(t *testing.T) {
+		items := getTask(task).Checklist
+
+		response := serveWithToken(server, alice, http.MethodPut, fmt.Sprintf("%v/%d/check", checklist, items[0].ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+		response = serveWithToken(server, alice, http.MethodPut, fmt.Sprintf("%v/%d/check", checklist, items[1].ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+		response = serveWithToken(server, alice, http.MethodDelete, fmt.Sprintf("%v/%d/check", checklist, items[1].ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		got := getTask(task)
+		if got.ChecklistDone != 1 || got.ChecklistTotal != 3 {
+			t.Errorf("got %d of %d items checked, want 1 of 3", got.ChecklistDone, got.ChecklistTotal)
+		}
+	})
+
+	t.Run("Reorder the checklist", func(t *testing.T) {
+		items := getTask(task).Checklist
+
+		response := serveWithToken(server, alice, http.MethodPut, checklist, fmt.Sprintf(`{"order": [%d, %d]}`, items[2].ID, items[0].ID))
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = serveWithToken(server, alice, http.MethodPut, checklist, fmt.Sprintf(`{"order": [%d, %d, %d]}`, items[2].ID, items[0].ID, items[1].ID))
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		got := getTask(task).Checklist
+		if got[0].Text != "tickets" || got[1].Text != "passport" || got[2].Text != "charger" {
+			t.Errorf("got checklist %v, want tickets, passport and charger", got)
+		}
+	})
+
+	t.Run("Edit and delete items", func(t *testing.T) {
+		items := getTask(task).Checklist
+
+		response := serveWithToken(server, alice, http.MethodPut, fmt.Sprintf("%v/%d", checklist, items[2].ID), `{"text": "phone charger"}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodDelete, fmt.Sprintf("%v/%d", checklist, items[0].ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodDelete, fmt.Sprintf("%v/%d", checklist, items[0].ID), "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+
+		got := getTask(task).Checklist
+		if len(got) != 2 || got[1].Text != "phone charger" {
+			t.Errorf("got checklist %v, want passport and phone charger", got)
+		}
+	})
+
+	t.Run("Completing a task can check the remaining items", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, task+"/complete", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if got := getTask(task); got.ChecklistDone != 1 {
+			t.Errorf("got %d items checked, want the checklist unchanged", got.ChecklistDone)
+		}
+
+		serveWithToken(server, alice, http.MethodDelete, task+"/complete", "")
+		response = serveWithToken(server, alice, http.MethodPut, task+"/complete?checklist=complete", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if got := getTask(task); got.ChecklistDone != 2 {
+			t.Errorf("got %d items checked, want all 2", got.ChecklistDone)
+		}
+	})
+
+	t.Run("Repeating tasks start with an unchecked checklist", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, "/projects/trip/tasks", `{"name": "plants", "deadline": "2030-01-01T10:00:00Z", "recurrence": "FREQ=WEEKLY"}`)
+		serveWithToken(server, alice, http.MethodPost, "/projects/trip/tasks/plants/checklist", `{"text": "water", "checked": true}`)
+
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/trip/tasks/plants/complete", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if got := getTask("/projects/trip/tasks/plants"); got.ChecklistDone != 0 || got.ChecklistTotal != 1 {
+			t.Errorf("got %d of %d items checked, want 0 of 1", got.ChecklistDone, got.ChecklistTotal)
+		}
+	})
+}
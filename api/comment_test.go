@@ -0,0 +1,134 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for the comment routes of tasks
+func TestComments(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+
+	getComments := func(url string) []model.Comment {
+		var comments []model.Comment
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, url, "").Body).Decode(&comments)
+		return comments
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "website"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/website/tasks", `{"name": "redesign"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/website/members", `{"user": "bob", "role": "editor"}`)
+
+	comments := "/projects/website/tasks/redesign/comments"
+
+	t.Run("Create comments and replies", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, comments, `{"body": "Which colors?"}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+		first := getComments(comments)[0].ID
+
+		response = serveWithToken(server, bob, http.MethodPost, comments, fmt.Sprintf(`{"body": "Blue", "parent_id": %d}`, first))
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+		reply := getComments(comments)[0].Replies[0].ID
+
+		response = serveWithToken(server, alice, http.MethodPost, comments, fmt.Sprintf(`{"body": "**Dark** blue?", "parent_id": %d}`, reply))
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, alice, http.MethodPost, comments, `{"body": "Fonts are done"}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+	})
+
+	t.Run("Empty comments and unknown parents are rejected", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, comments, `{"body": "  "}`)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = serveWithToken(server, alice, http.MethodPost, comments, `{"body": "Hi", "parent_id": 999}`)
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("List threads oldest first", func(t *testing.T) {
+		got := getComments(comments + "?render=html")
+
+		if len(got) != 2 || got[0].Body != "Which colors?" || got[1].Body != "Fonts are done" {
+			t.Fatalf("got comments %v, want the two top-level comments", got)
+		}
+		if len(got[0].Replies) != 1 || got[0].Replies[0].User.Name != "bob" || len(got[0].Replies[0].Replies) != 1 {
+			t.Fatalf("got replies %v, want the reply of bob with one answer", got[0].Replies)
+		}
+		assertResponseBody(t, got[0].Replies[0].Replies[0].BodyHTML, "<p><strong>Dark</strong> blue?</p>\n")
+	})
+
+	t.Run("Paginate top-level comments", func(t *testing.T) {
+		got := getComments(comments + "?limit=1&offset=1")
+
+		if len(got) != 1 || got[0].Body != "Fonts are done" {
+			t.Errorf("got comments %v, want the second comment", got)
+		}
+	})
+
+	t.Run("Only the author can edit a comment", func(t *testing.T) {
+		first := getComments(comments)[0]
+		url := fmt.Sprintf("%v/%d", comments, first.ID)
+
+		response := serveWithToken(server, bob, http.MethodPut, url, `{"body": "Which fonts?"}`)
+		assertResponseStatus(t, response.Code, http.StatusForbidden)
+
+		response = serveWithToken(server, alice, http.MethodPut, url, `{"body": "Which colors and fonts?"}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		edited := getComments(comments)[0]
+		if edited.Body != "Which colors and fonts?" || edited.EditedAt == nil {
+			t.Errorf("got body %q edited at %v, want the new body with edit time", edited.Body, edited.EditedAt)
+		}
+	})
+
+	t.Run("Deleting a comment deletes its replies", func(t *testing.T) {
+		first := getComments(comments)[0]
+
+		response := serveWithToken(server, alice, http.MethodDelete, fmt.Sprintf("%v/%d", comments, first.ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if reply := db.GetComment(first.Replies[0].ID); reply.ID != 0 {
+			t.Error("reply was not deleted")
+		}
+		if answer := db.GetComment(first.Replies[0].Replies[0].ID); answer.ID != 0 {
+			t.Error("answer to the reply was not deleted")
+		}
+	})
+
+	t.Run("Purging a task deletes its comments", func(t *testing.T) {
+		remaining := getComments(comments)[0]
+
+		response := serveWithToken(server, alice, http.MethodDelete, "/projects/website/tasks/redesign", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodDelete, fmt.Sprintf("/trash/tasks/%d", remaining.TaskID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if comment := db.GetComment(remaining.ID); comment.ID != 0 {
+			t.Error("comment of the purged task was not deleted")
+		}
+	})
+}
+
+// Tests for pages of comments that are out of range
+func TestCommentPageValidation(t *testing.T) {
+	server, _ := setupTaskTests()
+
+	for _, query := range []string{"limit=ten", "limit=0", "limit=201", "offset=-1"} {
+		t.Run(query, func(t *testing.T) {
+			request := newAuthenticatedRequest(http.MethodGet, "/projects/homework/tasks/math/comments?"+query, nil)
+			response := httptest.NewRecorder()
+
+			server.Router.ServeHTTP(response, request)
+
+			assertResponseStatus(t, response.Code, http.StatusBadRequest)
+		})
+	}
+}
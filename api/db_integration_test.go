@@ -2,21 +2,28 @@ package api_test
 
 // Integration tests for Database struct that implements TodoStore interface functions:
 //
-// GetProject(name string) model.Project
-// PostProject(name string) error
-// GetAllProjects() []model.Project
-// DeleteProject(name string) error
+// GetProject(workspaceID uint, name string) model.Project
+// PostProject(project model.Project) error
+// GetAllProjects(workspaceID, userID uint) []model.Project
+// DeleteProject(project model.Project) error
 // UpdateProject(project model.Project) error
 //
-// GetTask(projectID string, taskName string) model.Task
+// GetTask(project model.Project, taskName string) model.Task
 // PostTask(task model.Task) error
 // DeleteTask(task model.Task) error
 // UpdateTask(task model.Task) error
+//
+// GetUser(name string) model.User
+// PostUser(user model.User) error
+// PostToken(token model.Token) error
+// DeleteToken(hash string) error
+// GetUserByToken(hash string) model.User
 
 import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/mpfen/Go-Todo-REST-API/api/model"
 	"github.com/mpfen/Go-Todo-REST-API/api/store"
@@ -24,6 +31,12 @@ import (
 
 const testdbfile = "testdb.db"
 
+// owner of all projects created by the integration tests
+const testUserID = uint(1)
+
+// the default workspace is the first workspace of a new database
+const testWorkspaceID = uint(1)
+
 // create a testdb file
 func createTestDB(t *testing.T) {
 	testDB := []byte("")
@@ -45,14 +58,14 @@ func deleteTestDB(t *testing.T) {
 
 // populate test database with projects
 func populateTestDatabaseProjects(t *testing.T, db *store.Database) {
-	err := db.PostProject("homework")
+	err := db.PostProject(model.Project{Name: "homework", UserID: testUserID, WorkspaceID: testWorkspaceID})
 
 	if err != nil {
 		t.Fatalf("Error populating test database with projects: %v", err)
 		return
 	}
 
-	err = db.PostProject("cleaning")
+	err = db.PostProject(model.Project{Name: "cleaning", UserID: testUserID, WorkspaceID: testWorkspaceID})
 
 	if err != nil {
 		t.Fatalf("Error populating test database with projects: %v", err)
@@ -85,14 +98,14 @@ func TestDatabase(t *testing.T) {
 
 	db := store.NewDatabaseConnection(testdbfile)
 
-	// PostProject(name string) error
+	// PostProject(project model.Project) error
 	t.Run("Create a new project in database", func(t *testing.T) {
 		want := "TestDatabase"
-		err := db.PostProject(want)
+		err := db.PostProject(model.Project{Name: want, UserID: testUserID, WorkspaceID: testWorkspaceID})
 
 		assertError(t, "Create new project in db", err)
 
-		got := db.GetProject(want).Name
+		got := db.GetProject(testWorkspaceID, want).Name
 
 		if got != want {
 			t.Errorf("got %v, want %v", got, want)
@@ -105,24 +118,37 @@ func TestDatabase(t *testing.T) {
 	populateTestDatabaseProjects(t, db)
 
 	t.Run("Try to create an already existing project", func(t *testing.T) {
-		err := db.PostProject("TestDatabase")
+		err := db.PostProject(model.Project{Name: "TestDatabase", UserID: testUserID, WorkspaceID: testWorkspaceID})
 
 		if err == nil {
 			t.Errorf("Project should not have been created")
 		}
 	})
 
-	// DeleteProject(name string) error
+	t.Run("Create a project with the same name in another workspace", func(t *testing.T) {
+		err := db.PostProject(model.Project{Name: "TestDatabase", UserID: testUserID, WorkspaceID: testWorkspaceID + 1})
+		assertError(t, "Create project in other workspace", err)
+
+		if got := db.GetProject(testWorkspaceID+1, "TestDatabase"); got.WorkspaceID != testWorkspaceID+1 {
+			t.Errorf("got project of workspace %v, want %v", got.WorkspaceID, testWorkspaceID+1)
+		}
+
+		if projects := db.GetAllProjects(testWorkspaceID+1, testUserID); len(projects) != 1 {
+			t.Errorf("got %v projects in other workspace, want 1", len(projects))
+		}
+	})
+
+	// DeleteProject(project model.Project) error
 	t.Run("Delete a project", func(t *testing.T) {
-		err := db.DeleteProject("TestDatabase")
+		err := db.DeleteProject(db.GetProject(testWorkspaceID, "TestDatabase"))
 
 		assertError(t, "Project should have been deleted", err)
 	})
 
-	// GetProject(name string) model.Project
+	// GetProject(workspaceID uint, name string) model.Project
 	t.Run("Try to get project TestDatabse", func(t *testing.T) {
 		projectName := "homework"
-		project := db.GetProject(projectName)
+		project := db.GetProject(testWorkspaceID, projectName)
 
 		if project.Name != projectName {
 			t.Errorf("Project not found: got '%v' wanted %v", project.Name, projectName)
@@ -131,16 +157,16 @@ func TestDatabase(t *testing.T) {
 
 	t.Run("Try to get non existent project", func(t *testing.T) {
 		projectName := "NotTestDatabase"
-		project := db.GetProject(projectName)
+		project := db.GetProject(testWorkspaceID, projectName)
 
 		if project.Name != "" {
 			t.Error("No Project should have been found")
 		}
 	})
 
-	// GetAllProject() []model.Projects
+	// GetAllProject(workspaceID, userID uint) []model.Projects
 	t.Run("Get all projects in the database", func(t *testing.T) {
-		projects := db.GetAllProjects()
+		projects := db.GetAllProjects(testWorkspaceID, testUserID)
 
 		if i := len(projects); i != 2 {
 			t.Errorf("Not the right number of projects found: Found %v wanted 2", len(projects))
@@ -149,13 +175,13 @@ func TestDatabase(t *testing.T) {
 
 	// UpdateProject(project model.project) error
 	t.Run("Update the name of project cleaning", func(t *testing.T) {
-		project := db.GetProject("cleaning")
+		project := db.GetProject(testWorkspaceID, "cleaning")
 		project.Name = "springCleaning"
 		err := db.UpdateProject(project)
 
 		assertError(t, "update name of project failed: %v", err)
 
-		updatedProject := db.GetProject("springCleaning")
+		updatedProject := db.GetProject(testWorkspaceID, "springCleaning")
 
 		if updatedProject.Name == "" {
 			t.Error("Project was not updated")
@@ -163,7 +189,7 @@ func TestDatabase(t *testing.T) {
 	})
 
 	// PostTask(task model.Task) error
-	// GetTask(projectID string, taskName string) model.Task
+	// GetTask(project model.Project, taskName string) model.Task
 	t.Run("Create a new task math for project homework", func(t *testing.T) {
 		taskMath := model.Task{Name: "math", ProjectID: uint(2)}
 
@@ -171,7 +197,7 @@ func TestDatabase(t *testing.T) {
 
 		assertError(t, "Task creation failed", err)
 
-		task := db.GetTask("homework", "math")
+		task := db.GetTask(db.GetProject(testWorkspaceID, "homework"), "math")
 
 		if task.Name == "" {
 			t.Error("Newly created Task not found")
@@ -184,8 +210,8 @@ func TestDatabase(t *testing.T) {
 
 	// GetAllProjectTasks(projectName string) []model.Task
 	t.Run("Get all tasks from project homework", func(t *testing.T) {
-		project := db.GetProject("homework")
-		tasks := db.GetAllProjectTasks(project)
+		project := db.GetProject(testWorkspaceID, "homework")
+		tasks := db.GetAllProjectTasks(project, model.TaskFilter{})
 
 		if len(tasks) != 3 {
 			t.Errorf("Not the right numbers of tasks found: got %v want %v", len(tasks), 3)
@@ -193,8 +219,8 @@ func TestDatabase(t *testing.T) {
 	})
 
 	t.Run("Get all tasks from a project without tasks", func(t *testing.T) {
-		project := db.GetProject("cleaning")
-		tasks := db.GetAllProjectTasks(project)
+		project := db.GetProject(testWorkspaceID, "cleaning")
+		tasks := db.GetAllProjectTasks(project, model.TaskFilter{})
 
 		if len(tasks) != 0 {
 			t.Errorf("Not the right numbers of tasks found: got %v want %v", len(tasks), 3)
@@ -203,7 +229,7 @@ func TestDatabase(t *testing.T) {
 
 	// DeleteTask(task model.Task) error
 	t.Run("Delete a task", func(t *testing.T) {
-		task := db.GetTask("homework", "math")
+		task := db.GetTask(db.GetProject(testWorkspaceID, "homework"), "math")
 		err := db.DeleteTask(task)
 
 		assertError(t, "Task should have been deleted", err)
@@ -212,16 +238,74 @@ func TestDatabase(t *testing.T) {
 	// UpdateTask(task model.Task) error
 	t.Run("Update a task", func(t *testing.T) {
 		// Get task to update and change the name
-		task := db.GetTask("homework", "physics")
+		task := db.GetTask(db.GetProject(testWorkspaceID, "homework"), "physics")
 		task.Name = "newtonsLaw"
 
 		err := db.UpdateTask(task)
 		assertError(t, "tried to update task", err)
 
 		// check if task was updated
-		wasUpdated := db.GetTask("homework", "newtonsLaw")
+		wasUpdated := db.GetTask(db.GetProject(testWorkspaceID, "homework"), "newtonsLaw")
 		if wasUpdated.Name == "" {
 			t.Error("Task was not updated")
 		}
 	})
+
+	// PostUser(user model.User) error
+	// GetUser(name string) model.User
+	t.Run("Create a new user", func(t *testing.T) {
+		user := model.User{Name: "alice"}
+		err := user.SetPassword("correct horse")
+		assertError(t, "hash password", err)
+
+		err = db.PostUser(user)
+		assertError(t, "Create new user in db", err)
+
+		got := db.GetUser("alice")
+		if got.Name != "alice" || !got.CheckPassword("correct horse") {
+			t.Errorf("User was not stored correctly: %v", got)
+		}
+	})
+
+	t.Run("Try to create an already existing user", func(t *testing.T) {
+		err := db.PostUser(model.User{Name: "alice"})
+
+		if err == nil {
+			t.Errorf("User should not have been created")
+		}
+	})
+
+	// PostToken(token model.Token) error
+	// GetUserByToken(hash string) model.User
+	// DeleteToken(hash string) error
+	t.Run("Issue, resolve and revoke a token", func(t *testing.T) {
+		user := db.GetUser("alice")
+		token, secret, err := model.NewToken(user.ID)
+		assertError(t, "create token", err)
+
+		err = db.PostToken(token)
+		assertError(t, "store token", err)
+
+		if got := db.GetUserByToken(model.HashToken(secret)); got.Name != "alice" {
+			t.Errorf("Token did not resolve to user: got %q", got.Name)
+		}
+
+		err = db.DeleteToken(token.Hash)
+		assertError(t, "delete token", err)
+
+		if got := db.GetUserByToken(model.HashToken(secret)); got.ID != 0 {
+			t.Error("Revoked token should not resolve to a user")
+		}
+	})
+
+	t.Run("Expired tokens do not resolve", func(t *testing.T) {
+		user := db.GetUser("alice")
+		token, secret, _ := model.NewToken(user.ID)
+		token.ExpiresAt = time.Now().Add(-time.Minute)
+		db.PostToken(token)
+
+		if got := db.GetUserByToken(model.HashToken(secret)); got.ID != 0 {
+			t.Error("Expired token should not resolve to a user")
+		}
+	})
 }
@@ -0,0 +1,106 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for the blocker routes and completing blocked tasks
+func TestTaskDependencies(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+
+	workspace := db.GetWorkspace(model.DefaultWorkspaceName)
+	getTask := func(projectName, name string) model.Task {
+		return db.GetTask(db.GetProject(workspace.ID, projectName), name)
+	}
+	blockerBody := func(projectName, name string) string {
+		return fmt.Sprintf(`{"task_id": %d}`, getTask(projectName, name).ID)
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "backend"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "ops"}`)
+	serveWithToken(server, bob, http.MethodPost, "/projects", `{"name": "private"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/backend/tasks", `{"name": "write migration"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/backend/tasks", `{"name": "review"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/ops/tasks", `{"name": "deploy"}`)
+	serveWithToken(server, bob, http.MethodPost, "/projects/private/tasks", `{"name": "secret"}`)
+
+	t.Run("Add blockers across projects", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/ops/tasks/deploy/blockers", blockerBody("backend", "write migration"))
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects/backend/tasks/write migration/blockers", blockerBody("backend", "review"))
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+	})
+
+	t.Run("Cycles are rejected", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/backend/tasks/review/blockers", blockerBody("ops", "deploy"))
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects/backend/tasks/review/blockers", blockerBody("backend", "review"))
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Tasks of other users can not be blockers", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/ops/tasks/deploy/blockers", blockerBody("private", "secret"))
+
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("GET lists blockers and blocked tasks", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodGet, "/projects/backend/tasks/write migration", "")
+
+		var task model.Task
+		json.NewDecoder(response.Body).Decode(&task)
+
+		if len(task.BlockedBy) != 1 || task.BlockedBy[0].Name != "review" {
+			t.Errorf("got blockers %v, want review", task.BlockedBy)
+		}
+		if len(task.Blocks) != 1 || task.Blocks[0].Name != "deploy" {
+			t.Errorf("got blocked tasks %v, want deploy", task.Blocks)
+		}
+	})
+
+	t.Run("Blocked tasks can only be completed with force", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/ops/tasks/deploy/complete", "")
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+
+		response = serveWithToken(server, alice, http.MethodPut, "/projects/ops/tasks/deploy/complete?force=true", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("Tasks can be completed once their blockers are done", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/backend/tasks/review/complete", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodPut, "/projects/backend/tasks/write migration/complete", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("Remove a blocker", func(t *testing.T) {
+		url := fmt.Sprintf("/tasks/%d/blockers/%d", getTask("ops", "deploy").ID, getTask("backend", "write migration").ID)
+
+		response := serveWithToken(server, alice, http.MethodDelete, url, "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodDelete, url, "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("Cycles through tasks in the trash are rejected", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, "/projects/ops/tasks", `{"name": "monitor"}`)
+		serveWithToken(server, alice, http.MethodPost, "/projects/ops/tasks/deploy/blockers", blockerBody("backend", "write migration"))
+		serveWithToken(server, alice, http.MethodPost, "/projects/backend/tasks/review/blockers", blockerBody("ops", "monitor"))
+		serveWithToken(server, alice, http.MethodDelete, "/projects/backend/tasks/write migration", "")
+
+		// Restoring write migration would close the cycle deploy, write migration, review, monitor
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/ops/tasks/monitor/blockers", blockerBody("ops", "deploy"))
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+	})
+}
@@ -0,0 +1,103 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for Markdown descriptions of projects and tasks
+func TestDescriptions(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "garden", "description": "Plans for the **garden**"}`)
+
+	t.Run("Descriptions are stored as Markdown", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/garden/tasks", `{"name": "plant tulips", "description": "# Bulbs\n\n- 20 tulips\n- <script>alert(1)</script>\n\n[shop](javascript:alert(1))"}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/garden/tasks/plant tulips", "")
+
+		var task model.Task
+		json.NewDecoder(response.Body).Decode(&task)
+
+		if !strings.HasPrefix(task.Description, "# Bulbs") || task.DescriptionHTML != "" {
+			t.Errorf("got description %q and html %q, want only the Markdown", task.Description, task.DescriptionHTML)
+		}
+	})
+
+	t.Run("Render sanitized HTML", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodGet, "/projects/garden/tasks/plant tulips?render=html", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		var task model.Task
+		json.NewDecoder(response.Body).Decode(&task)
+
+		if !strings.Contains(task.DescriptionHTML, "<h1>Bulbs</h1>") || !strings.Contains(task.DescriptionHTML, "<li>20 tulips</li>") {
+			t.Errorf("Markdown was not rendered: %q", task.DescriptionHTML)
+		}
+		if strings.Contains(task.DescriptionHTML, "<script>") || strings.Contains(task.DescriptionHTML, "javascript:") {
+			t.Errorf("HTML was not sanitized: %q", task.DescriptionHTML)
+		}
+	})
+
+	t.Run("Render project descriptions", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodGet, "/projects?render=html", "")
+
+		var projects []model.Project
+		json.NewDecoder(response.Body).Decode(&projects)
+
+		if len(projects) != 1 || projects[0].DescriptionHTML != "<p>Plans for the <strong>garden</strong></p>\n" {
+			t.Errorf("got projects %v, want rendered description", projects)
+		}
+	})
+
+	t.Run("PATCH changes the description", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPatch, "/projects/garden/tasks/plant tulips", `{"description": "Done in autumn"}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		workspace := db.GetWorkspace(model.DefaultWorkspaceName)
+		task := db.GetTask(db.GetProject(workspace.ID, "garden"), "plant tulips")
+		assertResponseBody(t, task.Description, "Done in autumn")
+	})
+}
+
+// Tests for descriptions that are too large and unknown render formats
+func TestDescriptionValidation(t *testing.T) {
+	server, _ := setupTaskTests()
+
+	t.Run("Descriptions are limited in size", func(t *testing.T) {
+		description := strings.Repeat("a", 64*1024+1)
+
+		requests := []struct {
+			method, url, body string
+		}{
+			{http.MethodPost, "/projects/homework/tasks", `{"name": "mow", "description": "` + description + `"}`},
+			{http.MethodPatch, "/projects/homework/tasks/math", `{"description": "` + description + `"}`},
+			{http.MethodPut, "/projects/homework", `{"name": "homework", "description": "` + description + `"}`},
+		}
+		for _, r := range requests {
+			request := newAuthenticatedRequest(r.method, r.url, bytes.NewBufferString(r.body))
+			response := httptest.NewRecorder()
+
+			server.Router.ServeHTTP(response, request)
+
+			assertResponseStatus(t, response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Unknown render formats", func(t *testing.T) {
+		request := newAuthenticatedRequest(http.MethodGet, "/projects/homework?render=pdf", nil)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
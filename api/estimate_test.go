@@ -0,0 +1,104 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for estimates of tasks and the project summary
+func TestEstimates(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	getSummary := func(url string) model.ProjectSummary {
+		var summary model.ProjectSummary
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, url, "").Body).Decode(&summary)
+		return summary
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "sprint", "estimate_unit": "points"}`)
+
+	t.Run("Estimates are validated against the unit of the project", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/sprint/tasks", `{"name": "login", "estimate": 5, "remaining": 3}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects/sprint/tasks", `{"name": "search", "estimate": 2000}`)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects/sprint/tasks", `{"name": "search", "estimate": 8}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, alice, http.MethodPatch, "/projects/sprint/tasks/search", `{"remaining": -1}`)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects/sprint/tasks", `{"name": "docs"}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+	})
+
+	t.Run("Summarize open and done tasks", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPut, "/projects/sprint/tasks/login/complete", "")
+
+		got := getSummary("/projects/sprint/summary")
+		want := model.ProjectSummary{
+			ProjectID:    got.ProjectID,
+			Name:         "sprint",
+			EstimateUnit: model.EstimatePoints,
+			Open:         model.EstimateRollup{Tasks: 2, Estimate: 8, Remaining: 8},
+			Done:         model.EstimateRollup{Tasks: 1, Estimate: 5, Remaining: 0},
+			Unestimated:  1,
+		}
+		if got != want {
+			t.Errorf("got summary %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("Updates without a unit keep it", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/sprint", `{"name": "sprint", "description": "Two weeks"}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if got := getSummary("/projects/sprint/summary"); got.EstimateUnit != model.EstimatePoints {
+			t.Errorf("got unit %v, want points", got.EstimateUnit)
+		}
+	})
+
+	t.Run("The unit only changes without estimates", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/sprint", `{"name": "sprint", "estimate_unit": "minutes"}`)
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+
+		serveWithToken(server, alice, http.MethodPatch, "/projects/sprint/tasks/login", `{"estimate": null, "remaining": null}`)
+		serveWithToken(server, alice, http.MethodPatch, "/projects/sprint/tasks/search", `{"estimate": null}`)
+
+		response = serveWithToken(server, alice, http.MethodPut, "/projects/sprint", `{"name": "sprint", "estimate_unit": "minutes"}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if got := getSummary("/projects/sprint/summary"); got.EstimateUnit != model.EstimateMinutes || got.Unestimated != 3 {
+			t.Errorf("got summary %+v, want minutes without estimates", got)
+		}
+	})
+}
+
+// Tests for estimate units the projects do not accept
+func TestEstimateUnitValidation(t *testing.T) {
+	server, _ := setUpProjectTests()
+
+	t.Run("Projects only accept known units", func(t *testing.T) {
+		request := newAuthenticatedRequest(http.MethodPost, "/projects", bytes.NewBufferString(`{"name": "backlog", "estimate_unit": "hours"}`))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		request = newAuthenticatedRequest(http.MethodPut, "/projects/homework", bytes.NewBufferString(`{"name": "homework", "estimate_unit": "hours"}`))
+		response = httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
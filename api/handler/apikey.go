@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Lifetime of an API key created without expires_at
+const defaultAPIKeyLifetime = 90 * 24 * time.Hour
+
+// Handler for POST /api-keys
+// Returns the plaintext key once, only its hash is stored
+func PostAPIKeyHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeAPIKeyFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	if request.Name == "" || len(request.Scopes) == 0 {
+		sendJSONResponse(w, "A name and at least one scope are required", http.StatusBadRequest)
+		return
+	}
+
+	for _, scope := range request.Scopes {
+		if !scope.Valid() {
+			sendJSONResponse(w, fmt.Sprintf("Unknown scope %v", scope), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if request.ExpiresAt.IsZero() {
+		request.ExpiresAt = time.Now().Add(defaultAPIKeyLifetime)
+	} else if request.ExpiresAt.Before(time.Now()) {
+		sendJSONResponse(w, "expires_at must be in the future", http.StatusBadRequest)
+		return
+	}
+
+	key, secret, err := model.NewAPIKey(currentUser(r).ID, request.Name, request.Scopes, request.ExpiresAt)
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := p.PostAPIKey(key); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	created := p.GetAPIKey(key.Hash)
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceAPIKey, ResourceID: created.ID}, nil, created)
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"key":        secret,
+		"name":       key.Name,
+		"prefix":     key.Prefix,
+		"scopes":     key.Scopes,
+		"expires_at": key.ExpiresAt,
+	})
+}
+
+// Handler for GET /api-keys
+func GetAllAPIKeysHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p.GetAllAPIKeys(currentUser(r).ID))
+}
+
+// Handler for DELETE /api-keys/{id}
+// Revokes the key, the row is kept to audit its usage
+func DeleteAPIKeyHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, _ := strconv.ParseUint(vars["id"], 10, 64)
+
+	key := p.GetAPIKeyByID(uint(id))
+
+	if key.ID == 0 || key.UserID != currentUser(r).ID {
+		sendJSONResponse(w, "No API key with this id found", http.StatusNotFound)
+		return
+	}
+
+	if key.RevokedAt != nil {
+		sendJSONResponse(w, "API key is already revoked", http.StatusBadRequest)
+		return
+	}
+
+	before := key
+	key.Revoke()
+	err := p.UpdateAPIKey(key)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditRevoke, ResourceType: model.ResourceAPIKey, ResourceID: key.ID}, before, key)
+
+	sendJSONResponse(w, "API key successfully revoked", http.StatusOK)
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Value of ?assignee= that lists tasks without assignees
+const unassignedFilter = "unassigned"
+
+// Handler for POST /projects/{name}/tasks/{taskName}/assignees and POST /tasks/{taskID}/assignees
+// Only members of the project can be assigned
+func PostTaskAssigneeHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeAssigneeFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if project and task exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	membership := checkIfMemberExistsOr404(p, w, project, request.User)
+	if membership.ID == 0 {
+		return
+	}
+
+	user := p.GetUserByID(membership.UserID)
+	if isAssigned(task, user) {
+		sendJSONResponse(w, fmt.Sprintf("User %v is already assigned to task %v", user.Name, task.Name), http.StatusBadRequest)
+		return
+	}
+
+	err := p.AddTaskAssignee(task, user)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceTask, ResourceID: task.ID, ProjectID: task.ProjectID}, task, p.GetTaskByID(task.ID))
+
+	sendJSONResponse(w, fmt.Sprintf("User %v assigned to task %v", user.Name, task.Name), http.StatusCreated)
+}
+
+// Handler for DELETE /projects/{name}/tasks/{taskName}/assignees/{userName} and DELETE /tasks/{taskID}/assignees/{userName}
+func DeleteTaskAssigneeHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	user := p.GetUser(mux.Vars(r)["userName"])
+
+	if user.Name == "" || !isAssigned(task, user) {
+		sendJSONResponse(w, fmt.Sprintf("User %v is not assigned to task %v", mux.Vars(r)["userName"], task.Name), http.StatusNotFound)
+		return
+	}
+
+	err := p.DeleteTaskAssignee(task, user)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceTask, ResourceID: task.ID, ProjectID: task.ProjectID}, task, p.GetTaskByID(task.ID))
+
+	sendJSONResponse(w, "Assignee successfully removed", http.StatusOK)
+}
+
+// Handler for GET /me/tasks
+// Returns the open tasks assigned to the current user in all projects, the next deadline first
+func GetMyTasksHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	includeFuture, ok := checkIncludeFutureOr400(w, r)
+	if !ok {
+		return
+	}
+
+	tasks := p.GetAssignedTasks(currentUser(r).ID)
+	if !includeFuture {
+		tasks = hideFutureTasks(tasks)
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tasks)
+}
+
+// Reports whether the user is assigned to the task
+func isAssigned(task model.Task, user model.User) bool {
+	for _, assignee := range task.Assignees {
+		if assignee.ID == user.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the user names of the ?assignee= query parameters, which can be repeated or comma separated,
+// and whether unassigned tasks were requested
+func assigneesFromQuery(r *http.Request) ([]string, bool) {
+	names := []string{}
+	unassigned := false
+	seen := map[string]bool{}
+
+	for _, value := range r.URL.Query()["assignee"] {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			switch {
+			case name == unassignedFilter:
+				unassigned = true
+			case name != "" && !seen[name]:
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names, unassigned
+}
@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/blob"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Default size limits of attachments in bytes
+const (
+	DefaultMaxAttachmentSize        = 10 << 20
+	DefaultMaxProjectAttachmentSize = 100 << 20
+)
+
+// Where the files of attachments are stored and how large they can be
+type AttachmentStorage struct {
+	Files *blob.Store
+
+	// Maximum size of a single file and of all attachments of a project in bytes
+	MaxFileSize    int64
+	MaxProjectSize int64
+
+	// Held while a file is stored and its attachment created and while an unused file is deleted,
+	// so a file is not deleted between being found as already stored and being used again
+	mu sync.Mutex
+}
+
+// Returns a storage for the files with the default size limits
+func NewAttachmentStorage(files *blob.Store) *AttachmentStorage {
+	return &AttachmentStorage{Files: files, MaxFileSize: DefaultMaxAttachmentSize, MaxProjectSize: DefaultMaxProjectAttachmentSize}
+}
+
+// Handler for GET /projects/{name}/tasks/{taskName}/attachments and GET /tasks/{taskID}/attachments
+func GetAttachmentsHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleViewer)
+	if task.Name == "" {
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p.GetAttachments(task))
+}
+
+// Handler for POST /projects/{name}/tasks/{taskName}/attachments and POST /tasks/{taskID}/attachments
+// Stores the file of the multipart/form-data field "file"
+func PostAttachmentHandler(p store.TodoStore, a *AttachmentStorage, w http.ResponseWriter, r *http.Request) {
+	if !checkAttachmentStorageOr503(w, a) {
+		return
+	}
+
+	// Check if project and task exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		sendJSONResponse(w, "Attachments have to be uploaded as multipart/form-data", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// Find the file among the parts of the form
+	var part io.Reader
+	var fileName, contentType string
+	for {
+		next, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if next.FormName() == "file" {
+			part = next
+			fileName = cleanFileName(next.FileName())
+			contentType = next.Header.Get("content-type")
+			break
+		}
+	}
+
+	if part == nil {
+		sendJSONResponse(w, "The form field file is required", http.StatusBadRequest)
+		return
+	}
+
+	// The file has to fit into the limit of the file size and the space left in the project
+	limit := a.MaxFileSize
+	if left := a.MaxProjectSize - p.GetProjectAttachmentSize(project); left < limit {
+		limit = left
+	}
+
+	upload, err := a.Files.Upload(part, limit)
+
+	if errors.Is(err, blob.ErrTooLarge) {
+		sendJSONResponse(w, fmt.Sprintf("Files can be at most %d bytes and all attachments of a project %d bytes", a.MaxFileSize, a.MaxProjectSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		sendJSONResponse(w, "Problem storing file", http.StatusInternalServerError)
+		return
+	}
+	defer upload.Discard()
+
+	attachment, err := a.storeAttachment(p, upload, model.Attachment{
+		TaskID:      task.ID,
+		ProjectID:   project.ID,
+		UserID:      currentUser(r).ID,
+		FileName:    fileName,
+		ContentType: attachmentContentType(contentType, fileName),
+		Size:        upload.Size,
+		Hash:        upload.Hash,
+	})
+
+	if err != nil {
+		removeOrphanedFiles(p, a, []model.Attachment{{Hash: upload.Hash}})
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceAttachment, ResourceID: attachment.ID, ProjectID: project.ID}, nil, attachment)
+
+	sendJSONResponse(w, fmt.Sprintf("Attachment %d created", attachment.ID), http.StatusCreated)
+}
+
+// Handler for GET /projects/{name}/tasks/{taskName}/attachments/{attachmentID} and GET /tasks/{taskID}/attachments/{attachmentID}
+// Downloads the file of the attachment
+func GetAttachmentHandler(p store.TodoStore, a *AttachmentStorage, w http.ResponseWriter, r *http.Request) {
+	if !checkAttachmentStorageOr503(w, a) {
+		return
+	}
+
+	// Check if project, task and attachment exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleViewer)
+	if task.Name == "" {
+		return
+	}
+
+	attachment := checkIfAttachmentExistsOr404(p, w, r, task)
+	if attachment.ID == 0 {
+		return
+	}
+
+	file, err := a.Files.Open(attachment.Hash)
+	if err != nil {
+		sendJSONResponse(w, "The file of the attachment is missing", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("content-type", attachment.ContentType)
+	w.Header().Set("content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("x-content-type-options", "nosniff")
+	http.ServeContent(w, r, "", attachment.CreatedAt, file)
+}
+
+// Handler for DELETE /projects/{name}/tasks/{taskName}/attachments/{attachmentID} and DELETE /tasks/{taskID}/attachments/{attachmentID}
+func DeleteAttachmentHandler(p store.TodoStore, a *AttachmentStorage, w http.ResponseWriter, r *http.Request) {
+	// Check if project, task and attachment exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	attachment := checkIfAttachmentExistsOr404(p, w, r, task)
+	if attachment.ID == 0 {
+		return
+	}
+
+	err := p.DeleteAttachment(attachment)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	removeOrphanedFiles(p, a, []model.Attachment{attachment})
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditDelete, ResourceType: model.ResourceAttachment, ResourceID: attachment.ID, ProjectID: task.ProjectID}, attachment, nil)
+
+	sendJSONResponse(w, "Attachment successfully deleted", http.StatusOK)
+}
+
+// Sends a 503 message if no directory for attachments is configured
+func checkAttachmentStorageOr503(w http.ResponseWriter, a *AttachmentStorage) bool {
+	if a == nil || a.Files == nil {
+		sendJSONResponse(w, "Attachments are not configured", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// Checks if the attachment of the route belongs to the task and returns it or sends a 404 message
+func checkIfAttachmentExistsOr404(p store.TodoStore, w http.ResponseWriter, r *http.Request, task model.Task) model.Attachment {
+	id, _ := strconv.ParseUint(mux.Vars(r)["attachmentID"], 10, 64)
+	attachment := p.GetAttachment(uint(id))
+
+	if attachment.ID == 0 || attachment.TaskID != task.ID {
+		sendJSONResponse(w, fmt.Sprintf("No attachment with this id found on task %v", task.Name), http.StatusNotFound)
+		return model.Attachment{}
+	}
+	return attachment
+}
+
+// Stores the file of the upload and creates its attachment
+func (a *AttachmentStorage) storeAttachment(p store.TodoStore, upload *blob.Upload, attachment model.Attachment) (model.Attachment, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := upload.Commit(); err != nil {
+		return model.Attachment{}, err
+	}
+	return p.PostAttachment(attachment)
+}
+
+// Deletes the stored files of the attachments that no other attachment uses.
+// The attachments have to be deleted from the database first
+func removeOrphanedFiles(p store.TodoStore, a *AttachmentStorage, attachments []model.Attachment) {
+	if a == nil || a.Files == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, attachment := range attachments {
+		if p.CountAttachments(attachment.Hash) > 0 {
+			continue
+		}
+		if err := a.Files.Delete(attachment.Hash); err != nil {
+			log.Printf("could not delete file %v: %v", attachment.Hash, err)
+		}
+	}
+}
+
+// Returns the base name of an uploaded file without control characters
+func cleanFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	return name
+}
+
+// Returns the content type sent for the file or else the one of its extension
+func attachmentContentType(sent, fileName string) string {
+	if mediaType, params, err := mime.ParseMediaType(sent); err == nil && mediaType != "application/octet-stream" {
+		return mime.FormatMediaType(mediaType, params)
+	}
+	if byExtension := mime.TypeByExtension(filepath.Ext(fileName)); byExtension != "" {
+		return byExtension
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Default and maximum number of entries returned by GET /audit
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// Appends an entry for a change to the audit log. The actor is the current
+// user unless the entry already names one.
+// before and after are the resource before and after the change or nil.
+// A failed write is logged, the change itself has already been made
+func recordAudit(p store.TodoStore, r *http.Request, entry model.AuditEntry, before, after interface{}) {
+	if entry.Actor == "" {
+		user := currentUser(r)
+		entry.ActorID = user.ID
+		entry.Actor = user.Name
+	}
+	entry.Before = marshalAuditState(before)
+	entry.After = marshalAuditState(after)
+
+	if err := p.PostAuditEntry(entry); err != nil {
+		log.Printf("could not write audit entry for %v %v %d: %v", entry.Action, entry.ResourceType, entry.ResourceID, err)
+	}
+}
+
+func marshalAuditState(state interface{}) model.JSON {
+	if state == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// Handler for GET /audit
+// Supports the query parameters resource, resource_id, actor, from, to (RFC 3339) and limit
+func GetAuditHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := model.AuditFilter{
+		VisibleTo:    currentUser(r).ID,
+		Actor:        query.Get("actor"),
+		ResourceType: query.Get("resource"),
+		Limit:        defaultAuditLimit,
+	}
+
+	var err error
+
+	if v := query.Get("resource_id"); v != "" {
+		var id uint64
+		id, err = strconv.ParseUint(v, 10, 64)
+		filter.ResourceID = uint(id)
+	}
+	if v := query.Get("from"); v != "" && err == nil {
+		filter.From, err = time.Parse(time.RFC3339, v)
+	}
+	if v := query.Get("to"); v != "" && err == nil {
+		filter.To, err = time.Parse(time.RFC3339, v)
+	}
+	if v := query.Get("limit"); v != "" && err == nil {
+		filter.Limit, err = strconv.Atoi(v)
+		if err == nil && filter.Limit < 1 {
+			err = errors.New("limit must be positive")
+		}
+		if filter.Limit > maxAuditLimit {
+			filter.Limit = maxAuditLimit
+		}
+	}
+
+	if err != nil {
+		sendJSONResponse(w, "Invalid filter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p.GetAuditEntries(filter))
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/oidc"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+type contextKey int
+
+const (
+	userContextKey contextKey = iota
+	apiKeyContextKey
+)
+
+// Middleware that rejects requests without a valid bearer token
+// and stores the authenticated user in the request context.
+// The bearer token is either a login token or an API key
+func AuthenticationMiddleware(p store.TodoStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Already authenticated by OIDCMiddleware
+		if currentUser(r).ID != 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		secret := bearerToken(r)
+
+		if secret == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			sendJSONResponse(w, "Missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		var user model.User
+		ctx := r.Context()
+
+		if strings.HasPrefix(secret, model.APIKeyPrefix) {
+			key := p.GetAPIKey(model.HashToken(secret))
+
+			if key.Active() {
+				user = p.GetUserByID(key.UserID)
+				p.MarkAPIKeyUsed(key)
+				ctx = context.WithValue(ctx, apiKeyContextKey, key)
+			}
+		} else {
+			user = p.GetUserByToken(model.HashToken(secret))
+		}
+
+		if user.ID == 0 {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			sendJSONResponse(w, "Invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+
+		ctx = context.WithValue(ctx, userContextKey, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Middleware that authenticates requests with a JWT of the identity provider
+// configured in the validator. The sub claim is mapped to a local user that is
+// created on first use. Other bearer tokens are left to AuthenticationMiddleware
+func OIDCMiddleware(p store.TodoStore, v *oidc.Validator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := bearerToken(r)
+
+		if v == nil || !oidc.LooksLikeJWT(secret) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := v.Validate(secret)
+
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			sendJSONResponse(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		user := userForSubject(p, claims)
+
+		if user.ID == 0 {
+			sendJSONResponse(w, "No local user can be created for this subject", http.StatusForbidden)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userContextKey, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Returns the local user linked to the sub claim. Unknown subjects get a new user
+// named after the preferred_username claim, or the subject if that name is taken
+func userForSubject(p store.TodoStore, claims oidc.Claims) model.User {
+	user := p.GetUserBySubject(claims.Subject)
+
+	if user.ID != 0 {
+		return user
+	}
+
+	name := claims.PreferredUsername
+	if name == "" || p.GetUser(name).Name != "" {
+		name = claims.Subject
+	}
+
+	// Never link a subject to an existing local account
+	if p.GetUser(name).Name != "" {
+		return model.User{}
+	}
+
+	subject := claims.Subject
+	if err := p.PostUser(model.User{Name: name, Subject: &subject}); err != nil {
+		return model.User{}
+	}
+
+	return p.GetUserBySubject(subject)
+}
+
+// Middleware that rejects requests authenticated with an API key
+// that does not grant the scope required by the route.
+// Requests authenticated with a login token or a JWT are not restricted
+func ScopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := r.Context().Value(apiKeyContextKey).(model.APIKey)
+
+		if ok {
+			scope := requiredScope(r)
+
+			if !key.Allows(scope) {
+				sendJSONResponse(w, fmt.Sprintf("API key is missing the %v scope", scope), http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Returns the scope an API key needs for the matched route.
+// Managing API keys and reading the audit log requires the admin scope, routes below
+// /tasks require a tasks scope and all other routes a projects scope
+func requiredScope(r *http.Request) model.Scope {
+	template := ""
+	if route := mux.CurrentRoute(r); route != nil {
+		template, _ = route.GetPathTemplate()
+	}
+
+	read := r.Method == http.MethodGet || r.Method == http.MethodHead
+
+	switch {
+	case strings.HasPrefix(template, "/api-keys"), strings.HasPrefix(template, "/audit"):
+		return model.ScopeAdmin
+	case strings.Contains(template, "/tasks"):
+		if read {
+			return model.ScopeTasksRead
+		}
+		return model.ScopeTasksWrite
+	default:
+		if read {
+			return model.ScopeProjectsRead
+		}
+		return model.ScopeProjectsWrite
+	}
+}
+
+// Returns the token from the Authorization header or an empty string
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
+
+// Returns the user stored in the request context by AuthenticationMiddleware
+func currentUser(r *http.Request) model.User {
+	user, _ := r.Context().Value(userContextKey).(model.User)
+	return user
+}
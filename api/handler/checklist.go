@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Maximum length of the text of a checklist item in bytes
+const maxChecklistTextLength = 1024
+
+// Handler for GET /projects/{name}/tasks/{taskName}/checklist and GET /tasks/{taskID}/checklist
+// Returns the checklist items of the task in order
+func GetChecklistHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleViewer)
+	if task.Name == "" {
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p.GetChecklist(task))
+}
+
+// Handler for POST /projects/{name}/tasks/{taskName}/checklist and POST /tasks/{taskID}/checklist
+// New items are added to the end of the checklist
+func PostChecklistItemHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeChecklistItemFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	position := 0
+	if checklist := p.GetChecklist(task); len(checklist) > 0 {
+		position = checklist[len(checklist)-1].Position + 1
+	}
+
+	item, err := p.PostChecklistItem(model.ChecklistItem{TaskID: task.ID, Text: request.Text, Checked: request.Checked, Position: position})
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceChecklistItem, ResourceID: item.ID, ProjectID: task.ProjectID}, nil, item)
+
+	sendJSONResponse(w, fmt.Sprintf("Checklist item %d created", item.ID), http.StatusCreated)
+}
+
+// Handler for PUT /projects/{name}/tasks/{taskName}/checklist and PUT /tasks/{taskID}/checklist
+// Reorders the checklist, the body lists the ids of all items in their new order
+func ReorderChecklistHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request := struct {
+		Order []uint `json:"order"`
+	}{}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	checklist := p.GetChecklist(task)
+	byID := map[uint]model.ChecklistItem{}
+	for _, item := range checklist {
+		byID[item.ID] = item
+	}
+
+	if len(request.Order) != len(checklist) {
+		sendJSONResponse(w, fmt.Sprintf("The order has to list all %d items of the checklist", len(checklist)), http.StatusBadRequest)
+		return
+	}
+
+	reordered := make([]model.ChecklistItem, 0, len(request.Order))
+	for position, id := range request.Order {
+		item, ok := byID[id]
+		if !ok {
+			sendJSONResponse(w, fmt.Sprintf("Checklist item %d is not on task %v or listed twice", id, task.Name), http.StatusBadRequest)
+			return
+		}
+		delete(byID, id)
+
+		item.Position = position
+		reordered = append(reordered, item)
+	}
+
+	if err := p.UpdateChecklist(reordered); err != nil {
+		sendJSONResponse(w, "Problem reordering checklist", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceTask, ResourceID: task.ID, ProjectID: task.ProjectID}, checklist, reordered)
+
+	sendJSONResponse(w, "Checklist successfully reordered", http.StatusOK)
+}
+
+// Handler for PUT /projects/{name}/tasks/{taskName}/checklist/{itemID} and PUT /tasks/{taskID}/checklist/{itemID}
+// Replaces the text and the checked state of the item
+func UpdateChecklistItemHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeChecklistItemFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if project, task and item exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	item := checkIfChecklistItemExistsOr404(p, w, r, task)
+	if item.ID == 0 {
+		return
+	}
+
+	before := item
+	item.Text = request.Text
+	item.Checked = request.Checked
+
+	if err := p.UpdateChecklistItem(item); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceChecklistItem, ResourceID: item.ID, ProjectID: task.ProjectID}, before, item)
+
+	sendJSONResponse(w, "Checklist item successfully updated", http.StatusOK)
+}
+
+// Handler for PUT and DELETE /projects/{name}/tasks/{taskName}/checklist/{itemID}/check
+// and /tasks/{taskID}/checklist/{itemID}/check. PUT checks the item, DELETE unchecks it
+func CheckChecklistItemHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project, task and item exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	item := checkIfChecklistItemExistsOr404(p, w, r, task)
+	if item.ID == 0 {
+		return
+	}
+
+	before := item
+	var responseText, action string
+	if r.Method == "PUT" {
+		item.Checked = true
+		responseText = "Checklist item successfully checked"
+		action = model.AuditComplete
+	} else {
+		item.Checked = false
+		responseText = "Checklist item successfully unchecked"
+		action = model.AuditReopen
+	}
+
+	if err := p.UpdateChecklistItem(item); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: action, ResourceType: model.ResourceChecklistItem, ResourceID: item.ID, ProjectID: task.ProjectID}, before, item)
+
+	sendJSONResponse(w, responseText, http.StatusOK)
+}
+
+// Handler for DELETE /projects/{name}/tasks/{taskName}/checklist/{itemID} and DELETE /tasks/{taskID}/checklist/{itemID}
+func DeleteChecklistItemHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project, task and item exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	item := checkIfChecklistItemExistsOr404(p, w, r, task)
+	if item.ID == 0 {
+		return
+	}
+
+	if err := p.DeleteChecklistItem(item); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditDelete, ResourceType: model.ResourceChecklistItem, ResourceID: item.ID, ProjectID: task.ProjectID}, item, nil)
+
+	sendJSONResponse(w, "Checklist item successfully deleted", http.StatusOK)
+}
+
+// Checks if the checklist item of the route belongs to the task and returns it or sends a 404 message
+func checkIfChecklistItemExistsOr404(p store.TodoStore, w http.ResponseWriter, r *http.Request, task model.Task) model.ChecklistItem {
+	id, _ := strconv.ParseUint(mux.Vars(r)["itemID"], 10, 64)
+	item := p.GetChecklistItem(uint(id))
+
+	if item.ID == 0 || item.TaskID != task.ID {
+		sendJSONResponse(w, fmt.Sprintf("No checklist item with this id found on task %v", task.Name), http.StatusNotFound)
+		return model.ChecklistItem{}
+	}
+	return item
+}
+
+// Checks or unchecks all items of the checklist of the task that are in the other state.
+// Returns false and sends an error if the checklist could not be saved
+func setChecklistChecked(p store.TodoStore, w http.ResponseWriter, r *http.Request, task model.Task, checked bool) bool {
+	changed := []model.ChecklistItem{}
+	for _, item := range p.GetChecklist(task) {
+		if item.Checked != checked {
+			item.Checked = checked
+			changed = append(changed, item)
+		}
+	}
+	if len(changed) == 0 {
+		return true
+	}
+
+	if err := p.UpdateChecklist(changed); err != nil {
+		sendJSONResponse(w, "Problem updating checklist", http.StatusInternalServerError)
+		return false
+	}
+
+	action := model.AuditComplete
+	if !checked {
+		action = model.AuditReopen
+	}
+	for _, item := range changed {
+		before := item
+		before.Checked = !checked
+		recordAudit(p, r, model.AuditEntry{Action: action, ResourceType: model.ResourceChecklistItem, ResourceID: item.ID, ProjectID: task.ProjectID}, before, item)
+	}
+	return true
+}
+
+// Checks that the text of a checklist item is neither empty nor longer than the maximum
+func checkChecklistTextOr400(w http.ResponseWriter, text string) bool {
+	if text == "" {
+		sendJSONResponse(w, "The text of a checklist item is required", http.StatusBadRequest)
+		return false
+	}
+	if len(text) > maxChecklistTextLength {
+		sendJSONResponse(w, fmt.Sprintf("Checklist items can be at most %d bytes long", maxChecklistTextLength), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
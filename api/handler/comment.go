@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Default and maximum number of top-level comments returned by GET comments
+const (
+	defaultCommentLimit = 50
+	maxCommentLimit     = 200
+)
+
+// Maximum size of a comment in bytes
+const maxCommentLength = 16 * 1024
+
+// Handler for GET /projects/{name}/tasks/{taskName}/comments and GET /tasks/{taskID}/comments
+// Returns the top-level comments oldest first with their replies threaded below them.
+// Paginated with ?limit= and ?offset=, bodies are rendered as HTML with ?render=html
+func GetCommentsHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleViewer)
+	if task.Name == "" {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := model.CommentFilter{Limit: defaultCommentLimit}
+
+	var err error
+	if v := query.Get("limit"); v != "" {
+		filter.Limit, err = parseLimit(v, maxCommentLimit)
+	}
+	if v := query.Get("offset"); v != "" && err == nil {
+		filter.Offset, err = strconv.Atoi(v)
+		if err == nil && filter.Offset < 0 {
+			err = errors.New("offset must not be negative")
+		}
+	}
+
+	if err != nil {
+		sendJSONResponse(w, "Invalid page: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	html, ok := checkRenderOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Group the replies by the comment they answer
+	replies := map[uint][]model.Comment{}
+	for _, reply := range p.GetCommentReplies(task) {
+		replies[*reply.ParentID] = append(replies[*reply.ParentID], reply)
+	}
+
+	comments := p.GetComments(task, filter)
+	for i := range comments {
+		threadComment(&comments[i], replies, html)
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(comments)
+}
+
+// Handler for POST /projects/{name}/tasks/{taskName}/comments and POST /tasks/{taskID}/comments
+// A parent_id makes the comment a reply to another comment of the task
+func PostCommentHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeCommentFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	if request.ParentID != nil {
+		if parent := p.GetComment(*request.ParentID); parent.ID == 0 || parent.TaskID != task.ID {
+			sendJSONResponse(w, fmt.Sprintf("No comment with this id found on task %v", task.Name), http.StatusNotFound)
+			return
+		}
+	}
+
+	comment, err := p.PostComment(model.Comment{TaskID: task.ID, ParentID: request.ParentID, UserID: currentUser(r).ID, Body: request.Body})
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceComment, ResourceID: comment.ID, ProjectID: task.ProjectID}, nil, comment)
+
+	sendJSONResponse(w, fmt.Sprintf("Comment %d created", comment.ID), http.StatusCreated)
+}
+
+// Handler for PUT /projects/{name}/tasks/{taskName}/comments/{commentID} and PUT /tasks/{taskID}/comments/{commentID}
+// Only the author can edit a comment, the time of the edit is recorded in edited_at
+func UpdateCommentHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeCommentFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if project, task and comment exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	comment := checkIfCommentExistsOr404(p, w, r, task)
+	if comment.ID == 0 {
+		return
+	}
+
+	if comment.UserID != currentUser(r).ID {
+		sendJSONResponse(w, "Only the author can edit a comment", http.StatusForbidden)
+		return
+	}
+
+	before := comment
+	comment.Edit(request.Body)
+	err := p.UpdateComment(comment)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceComment, ResourceID: comment.ID, ProjectID: task.ProjectID}, before, comment)
+
+	sendJSONResponse(w, "Comment successfully updated", http.StatusOK)
+}
+
+// Handler for DELETE /projects/{name}/tasks/{taskName}/comments/{commentID} and DELETE /tasks/{taskID}/comments/{commentID}
+// The author and owners of the project can delete a comment, its replies are deleted with it
+func DeleteCommentHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project, task and comment exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	comment := checkIfCommentExistsOr404(p, w, r, task)
+	if comment.ID == 0 {
+		return
+	}
+
+	user := currentUser(r)
+	if comment.UserID != user.ID && p.GetMembership(project.ID, user.ID).Role != model.RoleOwner {
+		sendJSONResponse(w, "Only the author or owners of the project can delete a comment", http.StatusForbidden)
+		return
+	}
+
+	// Replies are deleted with the comment
+	thread, err := p.DeleteComment(comment)
+	if err != nil {
+		sendJSONResponse(w, fmt.Sprintf("Problem deleting comment: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	for _, deleted := range thread {
+		recordAudit(p, r, model.AuditEntry{Action: model.AuditDelete, ResourceType: model.ResourceComment, ResourceID: deleted.ID, ProjectID: task.ProjectID}, deleted, nil)
+	}
+
+	sendJSONResponse(w, "Comment successfully deleted", http.StatusOK)
+}
+
+// Checks if the comment of the route belongs to the task and returns it or sends a 404 message
+func checkIfCommentExistsOr404(p store.TodoStore, w http.ResponseWriter, r *http.Request, task model.Task) model.Comment {
+	id, _ := strconv.ParseUint(mux.Vars(r)["commentID"], 10, 64)
+	comment := p.GetComment(uint(id))
+
+	if comment.ID == 0 || comment.TaskID != task.ID {
+		sendJSONResponse(w, fmt.Sprintf("No comment with this id found on task %v", task.Name), http.StatusNotFound)
+		return model.Comment{}
+	}
+	return comment
+}
+
+// Attaches the replies to the comment and their replies to them
+func threadComment(comment *model.Comment, replies map[uint][]model.Comment, html bool) {
+	if html {
+		comment.BodyHTML = renderMarkdown(comment.Body)
+	}
+
+	comment.Replies = append([]model.Comment{}, replies[comment.ID]...)
+	for i := range comment.Replies {
+		threadComment(&comment.Replies[i], replies, html)
+	}
+}
+
+// Checks that the body of a comment is neither empty nor longer than the maximum
+func checkCommentBodyOr400(w http.ResponseWriter, body string) bool {
+	if strings.TrimSpace(body) == "" {
+		sendJSONResponse(w, "A comment body is required", http.StatusBadRequest)
+		return false
+	}
+	if len(body) > maxCommentLength {
+		sendJSONResponse(w, fmt.Sprintf("Comments can be at most %d bytes long", maxCommentLength), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Handler for POST /projects/{name}/tasks/{taskName}/blockers and POST /tasks/{taskID}/blockers
+// Adds the task with the id of the request as blocker, it can be in any project of the user
+func PostBlockerHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeDependencyFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	blocker := p.GetTaskByID(request.TaskID)
+
+	if blocker.ID == 0 || !canViewTask(p, r, blocker) {
+		sendJSONResponse(w, "No blocking task with this id found", http.StatusNotFound)
+		return
+	}
+
+	if blocker.ID == task.ID {
+		sendJSONResponse(w, "A task can not block itself", http.StatusBadRequest)
+		return
+	}
+
+	if p.GetTaskDependency(task.ID, blocker.ID).ID != 0 {
+		sendJSONResponse(w, fmt.Sprintf("Task %v is already blocked by task %v", task.Name, blocker.Name), http.StatusBadRequest)
+		return
+	}
+
+	if isBlockedBy(p, blocker, task) {
+		sendJSONResponse(w, fmt.Sprintf("Task %v is already blocked by task %v, the dependency would create a cycle", blocker.Name, task.Name), http.StatusConflict)
+		return
+	}
+
+	err := p.PostTaskDependency(model.TaskDependency{TaskID: task.ID, BlockerID: blocker.ID})
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	created := p.GetTaskDependency(task.ID, blocker.ID)
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceTaskDependency, ResourceID: created.ID, ProjectID: task.ProjectID}, nil, created)
+
+	sendJSONResponse(w, fmt.Sprintf("Task %v is blocked by task %v", task.Name, blocker.Name), http.StatusCreated)
+}
+
+// Handler for DELETE /projects/{name}/tasks/{taskName}/blockers/{blockerID} and DELETE /tasks/{taskID}/blockers/{blockerID}
+func DeleteBlockerHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	blockerID, _ := strconv.ParseUint(mux.Vars(r)["blockerID"], 10, 64)
+	dependency := p.GetTaskDependency(task.ID, uint(blockerID))
+
+	if dependency.ID == 0 {
+		sendJSONResponse(w, fmt.Sprintf("Task %v is not blocked by a task with this id", task.Name), http.StatusNotFound)
+		return
+	}
+
+	err := p.DeleteTaskDependency(dependency)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditDelete, ResourceType: model.ResourceTaskDependency, ResourceID: dependency.ID, ProjectID: task.ProjectID}, dependency, nil)
+
+	sendJSONResponse(w, "Blocker successfully removed", http.StatusOK)
+}
+
+// Reports whether the task is blocked by the blocker directly or through other tasks.
+// Tasks in the trash are followed too, restoring them must not close a cycle
+func isBlockedBy(p store.TodoStore, task, blocker model.Task) bool {
+	visited := map[uint]bool{task.ID: true}
+	queue := []uint{task.ID}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, next := range p.GetBlockerIDs(current) {
+			if next == blocker.ID {
+				return true
+			}
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// Returns the blockers of the task that are not done
+func getOpenBlockers(p store.TodoStore, task model.Task) []model.Task {
+	open := []model.Task{}
+
+	for _, blocker := range p.GetBlockers(task) {
+		if !blocker.Done {
+			open = append(open, blocker)
+		}
+	}
+	return open
+}
+
+// Reports whether the current user is a member of the project of the task
+func canViewTask(p store.TodoStore, r *http.Request, task model.Task) bool {
+	project := p.GetProjectByID(task.ProjectID)
+	user := currentUser(r)
+
+	return project.ID != 0 && canAccessWorkspace(p, project.WorkspaceID, user.ID) && p.GetMembership(project.ID, user.ID).Role.Valid()
+}
+
+// Returns references to the tasks the current user can view
+func getVisibleTaskReferences(p store.TodoStore, r *http.Request, tasks []model.Task) []model.TaskReference {
+	references := []model.TaskReference{}
+
+	for _, task := range tasks {
+		if canViewTask(p, r, task) {
+			references = append(references, model.NewTaskReference(task))
+		}
+	}
+	return references
+}
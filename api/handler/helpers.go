@@ -2,9 +2,15 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/rrule"
 	"github.com/mpfen/Go-Todo-REST-API/api/store"
 )
 
@@ -18,48 +24,640 @@ func sendJSONResponse(w http.ResponseWriter, message string, code int) {
 
 }
 
-// Checks if a project with that name exists and returns the project or sends 404 message
-func checkIfProjectExistsOr404(p store.TodoStore, w http.ResponseWriter, projectName string) model.Project {
-	project := p.GetProject(projectName)
+// Returns the workspace name of the route or the default workspace for routes without /workspaces/{workspace} prefix
+func workspaceName(r *http.Request) string {
+	if name := mux.Vars(r)["workspace"]; name != "" {
+		return name
+	}
+	return model.DefaultWorkspaceName
+}
+
+// Checks if the workspace of the route exists and the current user has at least the required role.
+// Every user is an editor of the default workspace. Returns the workspace or sends a 404 message
+// to non members and a 403 message to members with a lower role
+func checkIfWorkspaceExistsOr404(p store.TodoStore, w http.ResponseWriter, r *http.Request, role model.Role) model.Workspace {
+	workspace := p.GetWorkspace(workspaceName(r))
+
+	if workspace.Name == "" {
+		sendJSONResponse(w, "No workspace with this name found", http.StatusNotFound)
+		return model.Workspace{}
+	}
+
+	memberRole := model.RoleEditor
+	if !workspace.IsDefault() {
+		memberRole = p.GetWorkspaceMember(workspace.ID, currentUser(r).ID).Role
+	}
+
+	if !memberRole.Valid() {
+		sendJSONResponse(w, "No workspace with this name found", http.StatusNotFound)
+		return model.Workspace{}
+	}
+
+	if !memberRole.Allows(role) {
+		sendJSONResponse(w, fmt.Sprintf("This action requires the %v role in the workspace", role), http.StatusForbidden)
+		return model.Workspace{}
+	}
+	return workspace
+}
+
+// Checks if the project of the route exists and the current user has at least the required role. Projects are
+// addressed by {projectID} or by {name} in the workspace of the route. Returns the project or sends a 404 message
+// to non members and a 403 message to members with a lower role
+func checkIfProjectExistsOr404(p store.TodoStore, w http.ResponseWriter, r *http.Request, role model.Role) model.Project {
+	vars := mux.Vars(r)
+
+	if id, ok := vars["projectID"]; ok {
+		projectID, _ := strconv.ParseUint(id, 10, 64)
+		return checkProjectAccessOr404(p, w, r, p.GetProjectByID(uint(projectID)), role)
+	}
 
+	workspace := checkIfWorkspaceExistsOr404(p, w, r, model.RoleViewer)
+	if workspace.Name == "" {
+		return model.Project{}
+	}
+
+	return checkProjectAccessOr404(p, w, r, p.GetProject(workspace.ID, vars["name"]), role)
+}
+
+// Checks if the task of the route exists and the current user has at least the required role in its project.
+// Tasks are addressed by {taskID} or by {taskName} in the project of the route. Returns the project and
+// the task or sends a 404 message
+func checkIfTaskExistsOr404(p store.TodoStore, w http.ResponseWriter, r *http.Request, role model.Role) (model.Project, model.Task) {
+	vars := mux.Vars(r)
+
+	if id, ok := vars["taskID"]; ok {
+		taskID, _ := strconv.ParseUint(id, 10, 64)
+		task := p.GetTaskByID(uint(taskID))
+
+		if task.ID == 0 {
+			sendJSONResponse(w, "No task with that id exists", http.StatusNotFound)
+			return model.Project{}, model.Task{}
+		}
+
+		project := checkProjectAccessOr404(p, w, r, p.GetProjectByID(task.ProjectID), role)
+		if project.Name == "" {
+			return model.Project{}, model.Task{}
+		}
+		return project, task
+	}
+
+	project := checkIfProjectExistsOr404(p, w, r, role)
 	if project.Name == "" {
+		return model.Project{}, model.Task{}
+	}
+
+	task := p.GetTask(project, vars["taskName"])
+
+	if task.Name == "" {
+		sendJSONResponse(w, fmt.Sprintf("No task %v in project %v found", vars["taskName"], project.Name), http.StatusNotFound)
+		return model.Project{}, model.Task{}
+	}
+	return project, task
+}
+
+// Checks if the project exists and the current user has at least the required role in the project
+// and access to its workspace. Sends a 404 message to non members and a 403 message to members with a lower role
+func checkProjectAccessOr404(p store.TodoStore, w http.ResponseWriter, r *http.Request, project model.Project, role model.Role) model.Project {
+	if project.Name == "" || !canAccessWorkspace(p, project.WorkspaceID, currentUser(r).ID) {
 		sendJSONResponse(w, "No project with this name found", http.StatusNotFound)
-		return project
+		return model.Project{}
+	}
+
+	membership := p.GetMembership(project.ID, currentUser(r).ID)
+
+	if !membership.Role.Valid() {
+		sendJSONResponse(w, "No project with this name found", http.StatusNotFound)
+		return model.Project{}
+	}
+
+	if !membership.Role.Allows(role) {
+		sendJSONResponse(w, fmt.Sprintf("This action requires the %v role", role), http.StatusForbidden)
+		return model.Project{}
 	}
 	return project
 }
 
-// Checks if a task with that name exists in that project and returns the task or sends 404 message
-func checkIfTasksExistsOr404(p store.TodoStore, w http.ResponseWriter, taskName, projectName string) model.Task {
-	task := p.GetTask(projectName, taskName)
+// Reports whether the user can access the workspace, every user can access the default workspace
+func canAccessWorkspace(p store.TodoStore, workspaceID, userID uint) bool {
+	return workspaceRole(p, workspaceID, userID).Valid()
+}
 
-	if task.Name == "" {
-		sendJSONResponse(w, "No task with that name exists", http.StatusNotFound)
-		return task
+// Returns the role of the user in the workspace, every user is an editor of the default workspace
+func workspaceRole(p store.TodoStore, workspaceID, userID uint) model.Role {
+	if workspaceID == p.GetWorkspace(model.DefaultWorkspaceName).ID {
+		return model.RoleEditor
+	}
+	return p.GetWorkspaceMember(workspaceID, userID).Role
+}
+
+// Reports whether a project name would be mistaken for a project id in the routes
+func isNumericName(name string) bool {
+	_, err := strconv.ParseUint(name, 10, 64)
+	return err == nil
+}
+
+// Parses the priority of a task and returns it or sends a 400 message for unknown priorities
+func checkPriorityOr400(w http.ResponseWriter, value model.Priority) (model.Priority, bool) {
+	priority, ok := model.ParsePriority(string(value))
+
+	if !ok {
+		sendJSONResponse(w, "Priority must be one of none, low, medium, high or urgent", http.StatusBadRequest)
+		return priority, false
+	}
+	return priority, true
+}
+
+// Normalizes the recurrence rule and repeat mode of a task or sends a 400 message if they are invalid
+func checkRecurrenceOr400(w http.ResponseWriter, recurrence string, repeatFrom model.RepeatFrom) (string, model.RepeatFrom, bool) {
+	repeatFrom, ok := model.ParseRepeatFrom(string(repeatFrom))
+	if !ok {
+		sendJSONResponse(w, "repeat_from must be due or completion", http.StatusBadRequest)
+		return "", "", false
+	}
+
+	if recurrence == "" {
+		return "", repeatFrom, true
 	}
-	return task
+
+	rule, err := rrule.Parse(recurrence)
+	if err != nil {
+		sendJSONResponse(w, fmt.Sprintf("Invalid recurrence: %v", err), http.StatusBadRequest)
+		return "", "", false
+	}
+	return rule.String(), repeatFrom, true
+}
+
+// Parses the size of a page, out of range sizes are an error
+func parseLimit(value string, max int) (int, error) {
+	limit, err := strconv.Atoi(value)
+
+	if err == nil && (limit < 1 || limit > max) {
+		err = fmt.Errorf("limit must be between 1 and %d", max)
+	}
+	return limit, err
+}
+
+// Parses the estimate unit of a project or sends a 400 message for unknown units
+func checkEstimateUnitOr400(w http.ResponseWriter, value model.EstimateUnit) (model.EstimateUnit, bool) {
+	unit, ok := model.ParseEstimateUnit(string(value))
+
+	if !ok {
+		sendJSONResponse(w, "estimate_unit must be minutes or points", http.StatusBadRequest)
+		return unit, false
+	}
+	return unit, true
+}
+
+// Checks that estimate and remaining effort of a task are within the range of the unit of its project
+// or sends a 400 message
+func checkEstimateOr400(w http.ResponseWriter, unit model.EstimateUnit, estimate, remaining *int) bool {
+	unit, _ = model.ParseEstimateUnit(string(unit))
+
+	for i, value := range []*int{estimate, remaining} {
+		if value != nil && (*value < 0 || *value > unit.Max()) {
+			field := [...]string{"estimate", "remaining"}[i]
+			sendJSONResponse(w, fmt.Sprintf("%v must be between 0 and %d %v", field, unit.Max(), unit), http.StatusBadRequest)
+			return false
+		}
+	}
+	return true
+}
+
+// Checks if the tag of the route exists in the workspace of the route and the current user has at least
+// the required role in the workspace. Returns the tag or sends a 404 message
+func checkIfTagExistsOr404(p store.TodoStore, w http.ResponseWriter, r *http.Request, role model.Role) model.Tag {
+	workspace := checkIfWorkspaceExistsOr404(p, w, r, role)
+	if workspace.Name == "" {
+		return model.Tag{}
+	}
+
+	tag := p.GetTag(workspace.ID, strings.ToLower(mux.Vars(r)["tagName"]))
+
+	if tag.Name == "" {
+		sendJSONResponse(w, "No tag with this name found", http.StatusNotFound)
+		return model.Tag{}
+	}
+	return tag
+}
+
+// Normalizes a tag name to lower case or sends a 400 message for empty names and names with commas
+func checkTagNameOr400(w http.ResponseWriter, name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if name == "" {
+		sendJSONResponse(w, "A tag name is required", http.StatusBadRequest)
+		return "", false
+	}
+	if strings.Contains(name, ",") {
+		sendJSONResponse(w, "Tag names can not contain commas", http.StatusBadRequest)
+		return "", false
+	}
+	return name, true
+}
+
+// Checks if a user with that name exists and returns the user or sends 404 message
+func checkIfUserExistsOr404(p store.TodoStore, w http.ResponseWriter, userName string) model.User {
+	user := p.GetUser(userName)
+
+	if user.Name == "" {
+		sendJSONResponse(w, fmt.Sprintf("No user %v found", userName), http.StatusNotFound)
+		return user
+	}
+	return user
+}
+
+// Checks if a user is a member of the project and returns the membership or sends 404 message
+func checkIfMemberExistsOr404(p store.TodoStore, w http.ResponseWriter, project model.Project, userName string) model.Membership {
+	user := p.GetUser(userName)
+	membership := p.GetMembership(project.ID, user.ID)
+
+	if user.Name == "" || membership.ID == 0 {
+		sendJSONResponse(w, fmt.Sprintf("User %v is not a member of project %v", userName, project.Name), http.StatusNotFound)
+		return model.Membership{}
+	}
+	return membership
+}
+
+// Checks if a user is a member of the workspace and returns the membership or sends 404 message
+func checkIfWorkspaceMemberExistsOr404(p store.TodoStore, w http.ResponseWriter, workspace model.Workspace, userName string) model.WorkspaceMember {
+	user := p.GetUser(userName)
+	member := p.GetWorkspaceMember(workspace.ID, user.ID)
+
+	if user.Name == "" || member.ID == 0 {
+		sendJSONResponse(w, fmt.Sprintf("User %v is not a member of workspace %v", userName, workspace.Name), http.StatusNotFound)
+		return model.WorkspaceMember{}
+	}
+	return member
+}
+
+// Decodes a workspace struct from the request body. Returns it if successfull or send a http.StatusBadRequest
+func decodeWorkspaceFromRequestOr400(w http.ResponseWriter, r *http.Request) (model.Workspace, bool) {
+	workspace := model.Workspace{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&workspace); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return workspace, false
+	}
+	return workspace, true
 }
 
 // Decodes a project struct from the request body. Returns it if successfull or send a http.StatusBadRequest
-func decodeProjectFromRequestOr400(w http.ResponseWriter, r *http.Request) model.Project {
+// Descriptions longer than maxDescriptionLength are rejected
+func decodeProjectFromRequestOr400(w http.ResponseWriter, r *http.Request) (model.Project, bool) {
 	project := model.Project{}
 
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&project); err != nil {
 		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
-		return project
+		return project, false
 	}
-	return project
+	return project, checkDescriptionOr400(w, project.Description)
 }
 
 // Decodes a task struct from the request body. Returns it if successfull or send a http.StatusBadRequest
-func decodeTaskFromRequestOr400(w http.ResponseWriter, r *http.Request) model.Task {
+// Descriptions longer than maxDescriptionLength are rejected
+func decodeTaskFromRequestOr400(w http.ResponseWriter, r *http.Request) (model.Task, bool) {
 	task := model.Task{}
 
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&task); err != nil {
 		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
-		return task
+		return task, false
+	}
+	return task, checkDescriptionOr400(w, task.Description)
+}
+
+// Fields of a task that can be changed with PUT and PATCH. Done is changed by the complete route
+type taskRequest struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Priority    model.Priority `json:"priority"`
+	Deadline    *time.Time     `json:"deadline"`
+
+	StartAt      *time.Time `json:"start_at"`
+	ScheduledFor *time.Time `json:"scheduled_for"`
+
+	Recurrence string           `json:"recurrence"`
+	RepeatFrom model.RepeatFrom `json:"repeat_from"`
+
+	Estimate  *int `json:"estimate"`
+	Remaining *int `json:"remaining"`
+}
+
+// Returns the writable fields of a task
+func newTaskRequest(task model.Task) taskRequest {
+	return taskRequest{Name: task.Name, Description: task.Description, Priority: task.Priority, Deadline: task.Deadline, StartAt: task.StartAt, ScheduledFor: task.ScheduledFor,
+		Recurrence: task.Recurrence, RepeatFrom: task.RepeatFrom, Estimate: task.Estimate, Remaining: task.Remaining}
+}
+
+// Copies the fields onto the task
+func (t taskRequest) apply(task *model.Task) {
+	task.Name = t.Name
+	task.Description = t.Description
+	task.Priority = t.Priority
+	task.Deadline = t.Deadline
+	task.StartAt = t.StartAt
+	task.ScheduledFor = t.ScheduledFor
+	task.Recurrence = t.Recurrence
+	task.RepeatFrom = t.RepeatFrom
+	task.Estimate = t.Estimate
+	task.Remaining = t.Remaining
+}
+
+// Reports whether the JSON member name is a field of the request
+func (t taskRequest) has(field string) bool {
+	switch field {
+	case "name", "description", "priority", "deadline", "start_at", "scheduled_for", "recurrence", "repeat_from", "estimate", "remaining":
+		return true
+	}
+	return false
+}
+
+// Decodes a tag from the request body. Returns it if successfull or send a http.StatusBadRequest
+func decodeTagFromRequestOr400(w http.ResponseWriter, r *http.Request) (model.Tag, bool) {
+	tag := model.Tag{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&tag); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return tag, false
+	}
+	return tag, true
+}
+
+// Decodes the writable fields of a task from the request body. Returns them if successfull or send a http.StatusBadRequest
+// Descriptions longer than maxDescriptionLength are rejected
+func decodeTaskRequestFromRequestOr400(w http.ResponseWriter, r *http.Request) (taskRequest, bool) {
+	t := taskRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&t); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return t, false
+	}
+	return t, checkDescriptionOr400(w, t.Description)
+}
+
+// Blocking task sent to the POST blocker routes
+type dependencyRequest struct {
+	TaskID uint `json:"task_id"`
+}
+
+// Decodes the id of a blocking task from the request body. Returns it if successfull or send a http.StatusBadRequest
+func decodeDependencyFromRequestOr400(w http.ResponseWriter, r *http.Request) (dependencyRequest, bool) {
+	d := dependencyRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&d); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return d, false
+	}
+	return d, true
+}
+
+// Body of a comment and the comment it answers, sent to the POST and PUT comment routes
+type commentRequest struct {
+	Body     string `json:"body"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// Decodes a comment from the request body. Returns it if successfull or send a http.StatusBadRequest.
+// Empty comments and comments longer than maxCommentLength are rejected
+func decodeCommentFromRequestOr400(w http.ResponseWriter, r *http.Request) (commentRequest, bool) {
+	c := commentRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&c); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return c, false
+	}
+	return c, checkCommentBodyOr400(w, c.Body)
+}
+
+// Text and state of a checklist item, sent to the POST and PUT checklist routes
+type checklistItemRequest struct {
+	Text    string `json:"text"`
+	Checked bool   `json:"checked"`
+}
+
+// Decodes a checklist item from the request body. Returns it if successfull or send a http.StatusBadRequest.
+// Items without text and items longer than maxChecklistTextLength are rejected
+func decodeChecklistItemFromRequestOr400(w http.ResponseWriter, r *http.Request) (checklistItemRequest, bool) {
+	c := checklistItemRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&c); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return c, false
+	}
+	c.Text = strings.TrimSpace(c.Text)
+	return c, checkChecklistTextOr400(w, c.Text)
+}
+
+// Start, end and note of a time entry, sent to the POST time routes
+type timeEntryRequest struct {
+	Start *time.Time `json:"start"`
+	End   *time.Time `json:"end"`
+	Note  string     `json:"note"`
+}
+
+// Decodes a time entry from the request body. Returns it if successfull or send a http.StatusBadRequest.
+// Entries need a start and an end after it
+func decodeTimeEntryFromRequestOr400(w http.ResponseWriter, r *http.Request) (timeEntryRequest, bool) {
+	e := timeEntryRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&e); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return e, false
+	}
+	if e.Start == nil || e.End == nil {
+		sendJSONResponse(w, "A time entry needs a start and an end", http.StatusBadRequest)
+		return e, false
+	}
+	if !e.End.After(*e.Start) {
+		sendJSONResponse(w, "The end of a time entry has to be after its start", http.StatusBadRequest)
+		return e, false
+	}
+	return e, true
+}
+
+// Duration or time a task is snoozed for, sent to the PUT snooze routes
+type snoozeRequest struct {
+	For   string     `json:"for"`
+	Until *time.Time `json:"until"`
+
+	// Parsed from For
+	duration time.Duration
+}
+
+// Decodes a snooze from the request body. Returns it if successfull or send a http.StatusBadRequest.
+// Exactly one of for and until is required, durations are positive and until lies in the future
+func decodeSnoozeFromRequestOr400(w http.ResponseWriter, r *http.Request) (snoozeRequest, bool) {
+	s := snoozeRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&s); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return s, false
+	}
+
+	if (s.For == "") == (s.Until == nil) {
+		sendJSONResponse(w, "Either for or until is required", http.StatusBadRequest)
+		return s, false
+	}
+
+	if s.Until != nil {
+		if !s.Until.After(time.Now()) {
+			sendJSONResponse(w, "until has to be in the future", http.StatusBadRequest)
+			return s, false
+		}
+		return s, true
+	}
+
+	var err error
+	s.duration, err = parseSnoozeDuration(s.For)
+	if err != nil || s.duration <= 0 {
+		sendJSONResponse(w, "for has to be a positive duration like 90m, 4h or 3d", http.StatusBadRequest)
+		return s, false
+	}
+	return s, true
+}
+
+// Parses a duration of time.ParseDuration or a whole number of days like 3d
+func parseSnoozeDuration(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		return time.Duration(days) * 24 * time.Hour, err
+	}
+	return time.ParseDuration(value)
+}
+
+// Ways to resolve a name collision when moving a task to another project
+const (
+	conflictFail   = "fail"
+	conflictRename = "rename"
+)
+
+// Target project and conflict strategy sent to the POST move routes
+type moveRequest struct {
+	ProjectID  uint   `json:"project_id"`
+	OnConflict string `json:"on_conflict"`
+}
+
+// Decodes the target of a move from the request body. Returns it if successfull or send a http.StatusBadRequest
+func decodeMoveFromRequestOr400(w http.ResponseWriter, r *http.Request) (moveRequest, bool) {
+	m := moveRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&m); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return m, false
+	}
+
+	if m.OnConflict == "" {
+		m.OnConflict = conflictFail
+	}
+	if m.OnConflict != conflictFail && m.OnConflict != conflictRename {
+		sendJSONResponse(w, "on_conflict must be fail or rename", http.StatusBadRequest)
+		return m, false
+	}
+	return m, true
+}
+
+// Sibling a task or project is moved before or after, sent to the PUT position routes
+type positionRequest struct {
+	Before *uint `json:"before"`
+	After  *uint `json:"after"`
+}
+
+// Decodes the sibling from the request body. Returns it if successfull or send a http.StatusBadRequest.
+// Exactly one of before and after has to be set
+func decodePositionFromRequestOr400(w http.ResponseWriter, r *http.Request) (positionRequest, bool) {
+	m := positionRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&m); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return m, false
+	}
+	if (m.Before == nil) == (m.After == nil) {
+		sendJSONResponse(w, "Either before or after is required", http.StatusBadRequest)
+		return m, false
+	}
+	return m, true
+}
+
+// Returns the id of the sibling and whether the item goes after it
+func (m positionRequest) sibling() (uint, bool) {
+	if m.After != nil {
+		return *m.After, true
+	}
+	return *m.Before, false
+}
+
+// User sent to the POST assignee routes
+type assigneeRequest struct {
+	User string `json:"user"`
+}
+
+// Decodes the name of an assignee from the request body. Returns it if successfull or send a http.StatusBadRequest
+func decodeAssigneeFromRequestOr400(w http.ResponseWriter, r *http.Request) (assigneeRequest, bool) {
+	a := assigneeRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&a); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return a, false
+	}
+	return a, true
+}
+
+// User and role sent to the POST and PUT member routes of projects and workspaces
+type membershipRequest struct {
+	User string     `json:"user"`
+	Role model.Role `json:"role"`
+}
+
+// Decodes a user and role from the request body. Returns them if successfull or send a http.StatusBadRequest
+func decodeMembershipFromRequestOr400(w http.ResponseWriter, r *http.Request) (membershipRequest, bool) {
+	m := membershipRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&m); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return m, false
+	}
+	return m, true
+}
+
+// Decodes name, scopes and expiry of an API key from the request body. Returns them if successfull or send a http.StatusBadRequest
+func decodeAPIKeyFromRequestOr400(w http.ResponseWriter, r *http.Request) (model.APIKey, bool) {
+	key := model.APIKey{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&key); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return key, false
+	}
+	return key, true
+}
+
+// Name and password sent to POST /users and POST /login
+type credentials struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// Decodes name and password from the request body. Returns them if successfull or send a http.StatusBadRequest
+func decodeCredentialsFromRequestOr400(w http.ResponseWriter, r *http.Request) (credentials, bool) {
+	c := credentials{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&c); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return c, false
 	}
-	return task
+	return c, true
 }
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Maximum size of a task or project description in bytes
+const maxDescriptionLength = 64 * 1024
+
+// Renders GitHub flavored Markdown. Raw HTML is omitted and links
+// with dangerous schemes like javascript: are dropped
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Returns the Markdown as HTML
+func renderMarkdown(source string) string {
+	var buffer bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buffer); err != nil {
+		return ""
+	}
+	return buffer.String()
+}
+
+// Reports whether descriptions should be rendered for ?render=html or sends a 400 message for other values
+func checkRenderOr400(w http.ResponseWriter, r *http.Request) (html bool, ok bool) {
+	switch r.URL.Query().Get("render") {
+	case "":
+		return false, true
+	case "html":
+		return true, true
+	}
+	sendJSONResponse(w, "render must be html", http.StatusBadRequest)
+	return false, false
+}
+
+// Sends a 400 message if the description is longer than the maximum
+func checkDescriptionOr400(w http.ResponseWriter, description string) bool {
+	if len(description) > maxDescriptionLength {
+		sendJSONResponse(w, fmt.Sprintf("Descriptions can be at most %d bytes long", maxDescriptionLength), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// Sets the rendered descriptions of the tasks
+func renderTaskDescriptions(tasks []model.Task) {
+	for i := range tasks {
+		tasks[i].DescriptionHTML = renderMarkdown(tasks[i].Description)
+	}
+}
+
+// Sets the rendered descriptions of the projects
+func renderProjectDescriptions(projects []model.Project) {
+	for i := range projects {
+		projects[i].DescriptionHTML = renderMarkdown(projects[i].Description)
+	}
+}
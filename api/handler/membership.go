@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Handler for GET /projects/{name}/members
+func GetProjectMembersHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleViewer)
+	if project.Name == "" {
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p.GetProjectMembers(project.ID))
+}
+
+// Handler for POST /projects/{name}/members
+// Invites a user to the project with the given role
+func PostProjectMemberHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleOwner)
+	if project.Name == "" {
+		return
+	}
+
+	request, ok := decodeMembershipFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	if !request.Role.Valid() {
+		sendJSONResponse(w, "Role must be one of owner, editor or viewer", http.StatusBadRequest)
+		return
+	}
+
+	user := checkIfUserExistsOr404(p, w, request.User)
+	if user.Name == "" {
+		return
+	}
+
+	// Projects outside the default workspace can only be shared with workspace members
+	if !canAccessWorkspace(p, project.WorkspaceID, user.ID) {
+		sendJSONResponse(w, fmt.Sprintf("User %v is not a member of the workspace of project %v", user.Name, project.Name), http.StatusBadRequest)
+		return
+	}
+
+	if p.GetMembership(project.ID, user.ID).Role.Valid() {
+		sendJSONResponse(w, fmt.Sprintf("User %v is already a member of project %v", user.Name, project.Name), http.StatusBadRequest)
+		return
+	}
+
+	err := p.PostMembership(model.Membership{ProjectID: project.ID, UserID: user.ID, Role: request.Role})
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	created := p.GetMembership(project.ID, user.ID)
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceMembership, ResourceID: created.ID, ProjectID: project.ID}, nil, created)
+
+	sendJSONResponse(w, fmt.Sprintf("User %v added to project %v", user.Name, project.Name), http.StatusCreated)
+}
+
+// Handler for PUT /projects/{name}/members/{userName}
+// Changes the role of a member
+func UpdateProjectMemberHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleOwner)
+	if project.Name == "" {
+		return
+	}
+
+	membership := checkIfMemberExistsOr404(p, w, project, mux.Vars(r)["userName"])
+	if membership.ID == 0 {
+		return
+	}
+
+	request, ok := decodeMembershipFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	if !request.Role.Valid() {
+		sendJSONResponse(w, "Role must be one of owner, editor or viewer", http.StatusBadRequest)
+		return
+	}
+
+	if membership.Role == model.RoleOwner && request.Role != model.RoleOwner && isLastOwner(p, project) {
+		sendJSONResponse(w, "A project needs at least one owner", http.StatusBadRequest)
+		return
+	}
+
+	before := membership
+	membership.Role = request.Role
+	err := p.UpdateMembership(membership)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceMembership, ResourceID: membership.ID, ProjectID: project.ID}, before, membership)
+
+	sendJSONResponse(w, "Member successfully updated", http.StatusOK)
+}
+
+// Handler for DELETE /projects/{name}/members/{userName}
+// Owners can remove any member, every member can leave a project
+func DeleteProjectMemberHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	userName := mux.Vars(r)["userName"]
+
+	role := model.RoleOwner
+	if userName == currentUser(r).Name {
+		role = model.RoleViewer
+	}
+
+	project := checkIfProjectExistsOr404(p, w, r, role)
+	if project.Name == "" {
+		return
+	}
+
+	membership := checkIfMemberExistsOr404(p, w, project, userName)
+	if membership.ID == 0 {
+		return
+	}
+
+	if membership.Role == model.RoleOwner && isLastOwner(p, project) {
+		sendJSONResponse(w, "A project needs at least one owner", http.StatusBadRequest)
+		return
+	}
+
+	err := p.DeleteMembership(membership)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditDelete, ResourceType: model.ResourceMembership, ResourceID: membership.ID, ProjectID: project.ID}, membership, nil)
+
+	sendJSONResponse(w, "Member successfully removed", http.StatusOK)
+}
+
+// Reports whether the project has only a single owner left
+func isLastOwner(p store.TodoStore, project model.Project) bool {
+	owners := 0
+	for _, member := range p.GetProjectMembers(project.ID) {
+		if member.Role == model.RoleOwner {
+			owners++
+		}
+	}
+	return owners <= 1
+}
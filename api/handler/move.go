@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Handler for POST /projects/{name}/tasks/{taskName}/move and POST /tasks/{taskID}/move
+// Moves the task and its subtasks to the end of another project. Tasks with a name that is
+// taken in the project are only moved with on_conflict=rename, which appends a number to their name
+func MoveTaskToProjectHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeMoveFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if project and task exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	if task.ParentID != nil {
+		sendJSONResponse(w, "Subtasks are moved together with their parent task", http.StatusBadRequest)
+		return
+	}
+
+	target := checkProjectAccessOr404(p, w, r, p.GetProjectByID(request.ProjectID), model.RoleEditor)
+	if target.Name == "" {
+		return
+	}
+
+	if target.ID == project.ID {
+		sendJSONResponse(w, fmt.Sprintf("Task %v already is in project %v", task.Name, target.Name), http.StatusBadRequest)
+		return
+	}
+	if target.Archived {
+		sendJSONResponse(w, fmt.Sprintf("Project %v is archived, tasks can not be moved into it", target.Name), http.StatusConflict)
+		return
+	}
+
+	// Subtasks follow their parent, every task needs a free name in the target project
+	tasks := append([]model.Task{task}, getAllSubtasks(p, task)...)
+	taken := map[string]bool{}
+
+	for i := range tasks {
+		name := tasks[i].Name
+		for n := 2; taken[name] || p.GetTask(target, name).Name != ""; n++ {
+			if request.OnConflict != conflictRename {
+				sendJSONResponse(w, fmt.Sprintf("Project %v already has a task %v, use on_conflict=rename", target.Name, tasks[i].Name), http.StatusConflict)
+				return
+			}
+			name = fmt.Sprintf("%v (%d)", tasks[i].Name, n)
+		}
+
+		taken[name] = true
+		tasks[i].Name = name
+	}
+
+	if err := p.MoveTasks(tasks, target); err != nil {
+		sendJSONResponse(w, fmt.Sprintf("Problem moving task: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	for _, moved := range append([]model.Task{task}, getAllSubtasks(p, task)...) {
+		after := p.GetTaskByID(moved.ID)
+		recordAudit(p, r, model.AuditEntry{Action: model.AuditMove, ResourceType: model.ResourceTask, ResourceID: moved.ID, ProjectID: target.ID}, moved, after)
+	}
+
+	sendJSONResponse(w, fmt.Sprintf("Task %v moved to project %v", tasks[0].Name, target.Name), http.StatusOK)
+}
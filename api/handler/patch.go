@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+)
+
+// Content type of JSON merge patches
+const mergePatchContentType = "application/merge-patch+json"
+
+// Applies a JSON merge patch (RFC 7396) to the target document and returns the result.
+// Members of the patch set to null are removed from the target
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = mergePatch(targetObject[key], value)
+		}
+	}
+	return targetObject
+}
+
+// Applies a merge patch to the JSON representation of original and decodes the result into patched,
+// which has to be a zero value so removed members stay empty
+func applyMergePatch(original interface{}, patch map[string]interface{}, patched interface{}) error {
+	document, err := json.Marshal(original)
+	if err != nil {
+		return err
+	}
+
+	var target interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return err
+	}
+
+	result, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(result, patched)
+}
+
+// Decodes a JSON merge patch from the request body. Returns it if successfull or send a http.StatusBadRequest
+func decodeMergePatchFromRequestOr400(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("content-type"))
+	if contentType != "" && contentType != mergePatchContentType && contentType != jsonContentType {
+		sendJSONResponse(w, "PATCH requires content-type "+mergePatchContentType, http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+
+	patch := map[string]interface{}{}
+
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&patch); err != nil {
+		sendJSONResponse(w, "A merge patch has to be a JSON object: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return patch, true
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/rank"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Handler for PUT /projects/{name}/tasks/{taskName}/position and PUT /tasks/{taskID}/position
+// Moves the task before or after a sibling, a task of the same project with the same parent.
+// Only the position of the moved task changes
+func MoveTaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodePositionFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	id, after := request.sibling()
+	sibling := p.GetTaskByID(id)
+
+	if sibling.ID == 0 || sibling.ID == task.ID || sibling.ProjectID != task.ProjectID || !sameParent(sibling, task) {
+		sendJSONResponse(w, "Tasks can only be moved next to another task with the same parent in the same project", http.StatusBadRequest)
+		return
+	}
+
+	position, ok := positionNextToOr500(w, sibling.Position, p.GetAdjacentTaskPosition(sibling, after), after)
+	if !ok {
+		return
+	}
+
+	before := task
+	task.Position = position
+	err := p.UpdateTask(task)
+
+	if err != nil {
+		sendJSONResponse(w, "Problem updating task", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceTask, ResourceID: task.ID, ProjectID: task.ProjectID}, before, task)
+
+	sendJSONResponse(w, fmt.Sprintf("Task %v successfully moved", task.Name), http.StatusOK)
+}
+
+// Handler for PUT /projects/{name}/position
+// Moves the project before or after another project of its workspace. The order of the projects
+// is the same for all members, so moving requires the editor role in the workspace too
+func MoveProjectHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodePositionFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleEditor)
+	if project.Name == "" {
+		return
+	}
+
+	if !workspaceRole(p, project.WorkspaceID, currentUser(r).ID).Allows(model.RoleEditor) {
+		sendJSONResponse(w, fmt.Sprintf("Moving a project requires the %v role in the workspace", model.RoleEditor), http.StatusForbidden)
+		return
+	}
+
+	id, after := request.sibling()
+	sibling := checkProjectAccessOr404(p, w, r, p.GetProjectByID(id), model.RoleViewer)
+	if sibling.Name == "" {
+		return
+	}
+
+	if sibling.ID == project.ID || sibling.WorkspaceID != project.WorkspaceID {
+		sendJSONResponse(w, "Projects can only be moved next to another project of the same workspace", http.StatusBadRequest)
+		return
+	}
+
+	position, ok := positionNextToOr500(w, sibling.Position, p.GetAdjacentProjectPosition(sibling, after), after)
+	if !ok {
+		return
+	}
+
+	before := project
+	project.Position = position
+	err := p.UpdateProject(project)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceProject, ResourceID: project.ID, ProjectID: project.ID}, before, project)
+
+	sendJSONResponse(w, fmt.Sprintf("Project %v successfully moved", project.Name), http.StatusOK)
+}
+
+// Returns a position between the sibling and its neighbour on the side the item is moved to
+func positionNextToOr500(w http.ResponseWriter, sibling, neighbour string, after bool) (string, bool) {
+	lower, upper := neighbour, sibling
+	if after {
+		lower, upper = sibling, neighbour
+	}
+
+	position, err := rank.Between(lower, upper)
+	if err != nil {
+		sendJSONResponse(w, fmt.Sprintf("Problem computing position: %v", err), http.StatusInternalServerError)
+		return "", false
+	}
+	return position, true
+}
+
+// Reports whether both tasks are top-level tasks or subtasks of the same task
+func sameParent(a, b model.Task) bool {
+	if a.ParentID == nil || b.ParentID == nil {
+		return a.ParentID == b.ParentID
+	}
+	return *a.ParentID == *b.ParentID
+}
@@ -4,63 +4,128 @@ import (
 	"encoding/json"
 	"net/http"
 
-	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
 	"github.com/mpfen/Go-Todo-REST-API/api/store"
 )
 
 // Handler for GET /project/{name}
+// The description is rendered as HTML with ?render=html
 func GetProjectHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectName := vars["name"]
-
-	project := p.GetProject(projectName)
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleViewer)
 
 	if project.Name == "" {
-		sendJSONResponse(w, "No project with this name found", http.StatusNotFound)
 		return
 	} else {
+		html, ok := checkRenderOr400(w, r)
+		if !ok {
+			return
+		}
+		if html {
+			project.DescriptionHTML = renderMarkdown(project.Description)
+		}
+
 		w.Header().Set("content-type", jsonContentType)
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(project)
 	}
 }
 
+// Handler for GET /projects/{name}/summary
+// Sums up the estimates and remaining effort of the open and done tasks of the project
+func GetProjectSummaryHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleViewer)
+	if project.Name == "" {
+		return
+	}
+
+	summary := model.SummarizeProject(project, p.GetAllProjectTasks(project, model.TaskFilter{}))
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(summary)
+}
+
 // Handler for POST /projects/
 func PostProjectHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Only editors can create projects in a workspace
+	workspace := checkIfWorkspaceExistsOr404(p, w, r, model.RoleEditor)
+	if workspace.Name == "" {
+		return
+	}
+
 	// Decode project from request
-	project := decodeProjectFromRequestOr400(w, r)
+	project, ok := decodeProjectFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Numeric names would collide with the /projects/{projectID} routes
+	if isNumericName(project.Name) {
+		sendJSONResponse(w, "Project names can not be a number", http.StatusBadRequest)
+		return
+	}
+
+	project.EstimateUnit, ok = checkEstimateUnitOr400(w, project.EstimateUnit)
+	if !ok {
+		return
+	}
+
+	// New projects are owned by the current user
+	project.UserID = currentUser(r).ID
+	project.WorkspaceID = workspace.ID
 
 	// Create new project
-	err := p.PostProject(project.Name)
+	err := p.PostProject(project)
 
 	if err != nil {
 		sendJSONResponse(w, "Project with the same name already exists", http.StatusBadRequest)
 		return
 	}
 
+	created := p.GetProject(workspace.ID, project.Name)
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceProject, ResourceID: created.ID, ProjectID: created.ID}, nil, created)
+
 	sendJSONResponse(w, "Project successfully created", http.StatusCreated)
 }
 
 // Handler for GET /projects/
+// Descriptions are rendered as HTML with ?render=html
 func GetAllProjectsHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	workspace := checkIfWorkspaceExistsOr404(p, w, r, model.RoleViewer)
+	if workspace.Name == "" {
+		return
+	}
+
+	html, ok := checkRenderOr400(w, r)
+	if !ok {
+		return
+	}
+
+	projects := p.GetAllProjects(workspace.ID, currentUser(r).ID)
+	if html {
+		renderProjectDescriptions(projects)
+	}
+
 	w.Header().Set("content-type", jsonContentType)
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(p.GetAllProjects())
+	json.NewEncoder(w).Encode(projects)
 
 }
 
 // Handler for DELETE /projects/{name}
+// Moves the project with its tasks to the trash
 func DeleteProjectHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectName := vars["name"]
-
 	// Check if project exists
-	checkIfProjectExistsOr404(p, w, projectName)
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleOwner)
+	if project.Name == "" {
+		return
+	}
 
 	// Delete project if project exists
-	err := p.DeleteProject(projectName)
+	err := p.DeleteProject(project)
 
 	if err == nil {
+		recordAudit(p, r, model.AuditEntry{Action: model.AuditDelete, ResourceType: model.ResourceProject, ResourceID: project.ID, ProjectID: project.ID}, project, nil)
 		sendJSONResponse(w, "Project deleted", http.StatusOK)
 		return
 	} else {
@@ -70,17 +135,44 @@ func DeleteProjectHandler(p store.TodoStore, w http.ResponseWriter, r *http.Requ
 }
 
 // Handler for PUT /projects/{name}
+// Replaces the name, description and estimate unit of the project. The unit is kept if it is left out
+// and can only change while no task of the project has an estimate
 func UpdateProjectHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectName := vars["name"]
-
 	// Check if project exists
-	project := checkIfProjectExistsOr404(p, w, projectName)
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleOwner)
+	if project.Name == "" {
+		return
+	}
 
 	// Get new project name from request
-	newProject := decodeProjectFromRequestOr400(w, r)
+	newProject, ok := decodeProjectFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	if isNumericName(newProject.Name) {
+		sendJSONResponse(w, "Project names can not be a number", http.StatusBadRequest)
+		return
+	}
+
+	unit := project.EstimateUnit
+	if newProject.EstimateUnit != "" {
+		unit, ok = checkEstimateUnitOr400(w, newProject.EstimateUnit)
+		if !ok {
+			return
+		}
+	}
+
+	// Estimates can not be converted between minutes and points
+	if current, _ := model.ParseEstimateUnit(string(project.EstimateUnit)); unit != current && p.CountEstimatedTasks(project) > 0 {
+		sendJSONResponse(w, "The estimate unit can only change while no task of the project has an estimate", http.StatusConflict)
+		return
+	}
 
+	before := project
 	project.Name = newProject.Name
+	project.Description = newProject.Description
+	project.EstimateUnit = unit
 
 	// Update project
 	err := p.UpdateProject(project)
@@ -90,26 +182,35 @@ func UpdateProjectHandler(p store.TodoStore, w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	action := model.AuditRename
+	if project.Name == before.Name {
+		action = model.AuditUpdate
+	}
+	recordAudit(p, r, model.AuditEntry{Action: action, ResourceType: model.ResourceProject, ResourceID: project.ID, ProjectID: project.ID}, before, project)
+
 	sendJSONResponse(w, "Project successfully updated", http.StatusOK)
 }
 
 // Handler for PUT DELETE /projects/{name}/archive
 // PUT archived project - DELETE unarchives Project
 func ArchiveProjectHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectName := vars["name"]
-
 	// Check if project exists
-	project := checkIfProjectExistsOr404(p, w, projectName)
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleOwner)
+	if project.Name == "" {
+		return
+	}
 
 	// Archive or unarchive project
-	var responseText string
+	before := project
+	var responseText, action string
 	if r.Method == "PUT" {
 		project.ArchiveProject()
 		responseText = "Project successfully archived"
+		action = model.AuditArchive
 	} else {
 		project.UnArchiveProject()
 		responseText = "Project successfully unarchived"
+		action = model.AuditUnarchive
 	}
 
 	// Update project
@@ -120,5 +221,7 @@ func ArchiveProjectHandler(p store.TodoStore, w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	recordAudit(p, r, model.AuditEntry{Action: action, ResourceType: model.ResourceProject, ResourceID: project.ID, ProjectID: project.ID}, before, project)
+
 	sendJSONResponse(w, responseText, http.StatusOK)
 }
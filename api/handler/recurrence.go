@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/rrule"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Number of occurrences previewed by default and at most
+const (
+	defaultOccurrenceCount = 5
+	maxOccurrenceCount     = 100
+)
+
+// Handler for GET /projects/{name}/tasks/{taskName}/occurrences and GET /tasks/{taskID}/occurrences
+// Previews the next deadlines of a repeating task, ?count= sets their number
+func GetOccurrencesHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleViewer)
+	if task.Name == "" {
+		return
+	}
+
+	count := defaultOccurrenceCount
+	if value := r.URL.Query().Get("count"); value != "" {
+		var err error
+		count, err = strconv.Atoi(value)
+		if err != nil || count < 1 || count > maxOccurrenceCount {
+			sendJSONResponse(w, "count must be a number from 1 to 100", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if task.Recurrence == "" {
+		sendJSONResponse(w, "Task does not repeat", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := rrule.Parse(task.Recurrence)
+	if err != nil {
+		sendJSONResponse(w, "Problem parsing the recurrence of the task", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(rule.Occurrences(task.RecurrenceStart(time.Now()), count))
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Handler for GET /projects/{name}/history
+// Lists the revisions of the project, newest first
+func GetProjectHistoryHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project exists
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleViewer)
+	if project.Name == "" {
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p.GetRevisions(model.ResourceProject, project.ID))
+}
+
+// Handler for GET /projects/{name}/tasks/{taskName}/history and GET /tasks/{taskID}/history
+// Lists the revisions of the task, newest first
+func GetTaskHistoryHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleViewer)
+	if task.Name == "" {
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p.GetRevisions(model.ResourceTask, task.ID))
+}
+
+// Handler for POST /projects/{name}/tasks/{taskName}/history/{rev}/restore and POST /tasks/{taskID}/history/{rev}/restore
+// Sets the writable fields of the task back to their values before the revision. The task stays
+// in its project and keeps its state, the restore itself is recorded as a new revision.
+// Completed tasks keep their recurrence, a completed occurrence is not repeated again
+func RestoreTaskRevisionHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	number, _ := strconv.Atoi(mux.Vars(r)["rev"])
+	revision := p.GetRevision(model.ResourceTask, task.ID, number)
+
+	if revision.Number == 0 {
+		sendJSONResponse(w, fmt.Sprintf("Task %v has no revision %v", task.Name, mux.Vars(r)["rev"]), http.StatusNotFound)
+		return
+	}
+
+	snapshot := model.Task{}
+	if err := json.Unmarshal(revision.Snapshot, &snapshot); err != nil {
+		sendJSONResponse(w, fmt.Sprintf("Problem reading revision: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	restored := newTaskRequest(snapshot)
+	if task.Done {
+		restored.Recurrence, restored.RepeatFrom = task.Recurrence, task.RepeatFrom
+	}
+
+	// Validated like an update, the rules may have changed since the revision
+	if saveTaskUpdate(p, w, r, project, task, restored, model.AuditRestore) {
+		sendJSONResponse(w, fmt.Sprintf("Task %v restored to revision %v", restored.Name, revision.Number), http.StatusOK)
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Handler for PUT DELETE /projects/{name}/tasks/{taskName}/snooze and /tasks/{taskID}/snooze
+// PUT pushes the start of the task forward by a duration or to a time - DELETE clears the start
+func SnoozeTaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	before := task
+	var responseText string
+	if r.Method == "PUT" {
+		request, ok := decodeSnoozeFromRequestOr400(w, r)
+		if !ok {
+			return
+		}
+
+		if task.Done {
+			sendJSONResponse(w, fmt.Sprintf("Task %v is done and can not be snoozed", task.Name), http.StatusConflict)
+			return
+		}
+
+		if request.Until != nil {
+			task.SnoozeUntil(request.Until.Local())
+		} else {
+			task.SnoozeFor(request.duration, time.Now())
+		}
+		responseText = fmt.Sprintf("Task snoozed until %v", task.StartAt.Format(time.RFC3339))
+	} else {
+		task.StartAt = nil
+		responseText = "Task successfully unsnoozed"
+	}
+
+	if err := p.UpdateTask(task); err != nil {
+		sendJSONResponse(w, "Problem updating task", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceTask, ResourceID: task.ID, ProjectID: task.ProjectID}, before, task)
+	sendJSONResponse(w, responseText, http.StatusOK)
+}
+
+// Checks the ?include_future= parameter of task lists. Sends a 400 message if it is not a boolean
+func checkIncludeFutureOr400(w http.ResponseWriter, r *http.Request) (includeFuture bool, ok bool) {
+	value := r.URL.Query().Get("include_future")
+	if value == "" {
+		return false, true
+	}
+
+	includeFuture, err := strconv.ParseBool(value)
+	if err != nil {
+		sendJSONResponse(w, "include_future must be true or false", http.StatusBadRequest)
+		return false, false
+	}
+	return includeFuture, true
+}
+
+// Returns the tasks that have started by now
+func hideFutureTasks(tasks []model.Task) []model.Task {
+	now := time.Now()
+
+	started := []model.Task{}
+	for _, task := range tasks {
+		if task.Started(now) {
+			started = append(started, task)
+		}
+	}
+	return started
+}
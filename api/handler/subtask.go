@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Handler for GET /projects/{name}/tasks/{taskName}/subtasks and GET /tasks/{taskID}/subtasks
+// Descriptions are rendered as HTML with ?render=html
+func GetSubtasksHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleViewer)
+	if task.Name == "" {
+		return
+	}
+
+	html, ok := checkRenderOr400(w, r)
+	if !ok {
+		return
+	}
+
+	includeFuture, ok := checkIncludeFutureOr400(w, r)
+	if !ok {
+		return
+	}
+
+	subtasks := p.GetSubtasks(task)
+	if !includeFuture {
+		subtasks = hideFutureTasks(subtasks)
+	}
+	if html {
+		renderTaskDescriptions(subtasks)
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(subtasks)
+}
+
+// Handler for POST /projects/{name}/tasks/{taskName}/subtasks and POST /tasks/{taskID}/subtasks
+// Creates a task below the task of the route in the same project
+func PostSubtaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Decode task from request
+	task, ok := decodeTaskFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if project and parent task exist
+	project, parent := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if parent.Name == "" {
+		return
+	}
+
+	if getTaskDepth(p, parent) >= model.MaxTaskDepth {
+		sendJSONResponse(w, fmt.Sprintf("Subtasks can only be nested %d levels deep", model.MaxTaskDepth), http.StatusBadRequest)
+		return
+	}
+
+	task.ParentID = &parent.ID
+	createTask(p, w, r, project, task)
+}
+
+// Returns the level of the task in its hierarchy, top-level tasks are on level 1
+func getTaskDepth(p store.TodoStore, task model.Task) int {
+	depth := 1
+	for task.ParentID != nil && depth <= model.MaxTaskDepth {
+		task = p.GetTaskByID(*task.ParentID)
+		depth++
+	}
+	return depth
+}
+
+// Returns all subtasks of the task and their subtasks, parents come before their subtasks
+func getAllSubtasks(p store.TodoStore, task model.Task) []model.Task {
+	subtasks := []model.Task{}
+
+	for _, subtask := range p.GetSubtasks(task) {
+		subtasks = append(subtasks, subtask)
+		subtasks = append(subtasks, getAllSubtasks(p, subtask)...)
+	}
+	return subtasks
+}
+
+// Returns all subtasks of the task and their subtasks that are not done
+func getOpenSubtasks(p store.TodoStore, task model.Task) []model.Task {
+	open := []model.Task{}
+
+	for _, subtask := range getAllSubtasks(p, task) {
+		if !subtask.Done {
+			open = append(open, subtask)
+		}
+	}
+	return open
+}
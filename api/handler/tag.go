@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Handler for GET /tags and GET /workspaces/{workspace}/tags
+func GetAllTagsHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	workspace := checkIfWorkspaceExistsOr404(p, w, r, model.RoleViewer)
+	if workspace.Name == "" {
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p.GetAllTags(workspace.ID))
+}
+
+// Handler for GET /tags/{tagName} and GET /workspaces/{workspace}/tags/{tagName}
+func GetTagHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	tag := checkIfTagExistsOr404(p, w, r, model.RoleViewer)
+	if tag.Name == "" {
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tag)
+}
+
+// Handler for POST /tags and POST /workspaces/{workspace}/tags
+// Editors of a workspace can create tags
+func PostTagHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	tag, ok := decodeTagFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	name, ok := checkTagNameOr400(w, tag.Name)
+	if !ok {
+		return
+	}
+
+	workspace := checkIfWorkspaceExistsOr404(p, w, r, model.RoleEditor)
+	if workspace.Name == "" {
+		return
+	}
+
+	if p.GetTag(workspace.ID, name).Name != "" {
+		sendJSONResponse(w, "A tag with that name already exists in this workspace", http.StatusBadRequest)
+		return
+	}
+
+	err := p.PostTag(model.Tag{Name: name, WorkspaceID: workspace.ID, UserID: currentUser(r).ID})
+
+	if err != nil {
+		sendJSONResponse(w, "Tag with the same name already exists", http.StatusBadRequest)
+		return
+	}
+
+	created := p.GetTag(workspace.ID, name)
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceTag, ResourceID: created.ID}, nil, created)
+
+	sendJSONResponse(w, fmt.Sprintf("Tag %v created", name), http.StatusCreated)
+}
+
+// Handler for PUT /tags/{tagName} and PUT /workspaces/{workspace}/tags/{tagName}
+// Renames a tag, only its creator and owners of the workspace can change it
+func UpdateTagHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeTagFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	name, ok := checkTagNameOr400(w, request.Name)
+	if !ok {
+		return
+	}
+
+	tag := checkIfTagExistsOr404(p, w, r, model.RoleEditor)
+	if tag.Name == "" {
+		return
+	}
+
+	if !canManageTag(p, r, tag) {
+		sendJSONResponse(w, "Only the creator of the tag or owners of the workspace can change it", http.StatusForbidden)
+		return
+	}
+
+	if duplicate := p.GetTag(tag.WorkspaceID, name); duplicate.Name != "" && duplicate.ID != tag.ID {
+		sendJSONResponse(w, "A tag with that name already exists in this workspace", http.StatusBadRequest)
+		return
+	}
+
+	before := tag
+	tag.Name = name
+	err := p.UpdateTag(tag)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditRename, ResourceType: model.ResourceTag, ResourceID: tag.ID}, before, tag)
+
+	sendJSONResponse(w, "Tag successfully updated", http.StatusOK)
+}
+
+// Handler for DELETE /tags/{tagName} and DELETE /workspaces/{workspace}/tags/{tagName}
+// Deletes a tag and removes it from all tasks, only its creator and owners of the workspace can delete it
+func DeleteTagHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	tag := checkIfTagExistsOr404(p, w, r, model.RoleEditor)
+	if tag.Name == "" {
+		return
+	}
+
+	if !canManageTag(p, r, tag) {
+		sendJSONResponse(w, "Only the creator of the tag or owners of the workspace can delete it", http.StatusForbidden)
+		return
+	}
+
+	err := p.DeleteTag(tag)
+
+	if err != nil {
+		sendJSONResponse(w, fmt.Sprintf("Problem deleting tag: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditDelete, ResourceType: model.ResourceTag, ResourceID: tag.ID}, tag, nil)
+
+	sendJSONResponse(w, "Tag successfully deleted", http.StatusOK)
+}
+
+// Handler for POST /projects/{name}/tasks/{taskName}/tags and POST /tasks/{taskID}/tags
+// Attaches the tag with the name of the request, it has to be in the workspace of the project
+func PostTaskTagHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeTagFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if project and task exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	tag := p.GetTag(project.WorkspaceID, strings.ToLower(strings.TrimSpace(request.Name)))
+
+	if tag.Name == "" {
+		sendJSONResponse(w, "No tag with this name found", http.StatusNotFound)
+		return
+	}
+
+	if hasTag(task, tag) {
+		sendJSONResponse(w, fmt.Sprintf("Task %v already has tag %v", task.Name, tag.Name), http.StatusBadRequest)
+		return
+	}
+
+	err := p.AddTaskTag(task, tag)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceTask, ResourceID: task.ID, ProjectID: task.ProjectID}, task, p.GetTaskByID(task.ID))
+
+	sendJSONResponse(w, fmt.Sprintf("Tag %v added to task %v", tag.Name, task.Name), http.StatusCreated)
+}
+
+// Handler for DELETE /projects/{name}/tasks/{taskName}/tags/{tagName} and DELETE /tasks/{taskID}/tags/{tagName}
+func DeleteTaskTagHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	tag := p.GetTag(project.WorkspaceID, strings.ToLower(mux.Vars(r)["tagName"]))
+
+	if tag.Name == "" || !hasTag(task, tag) {
+		sendJSONResponse(w, fmt.Sprintf("Task %v has no tag with this name", task.Name), http.StatusNotFound)
+		return
+	}
+
+	err := p.DeleteTaskTag(task, tag)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceTask, ResourceID: task.ID, ProjectID: task.ProjectID}, task, p.GetTaskByID(task.ID))
+
+	sendJSONResponse(w, "Tag successfully removed", http.StatusOK)
+}
+
+// Reports whether the tag is attached to the task
+func hasTag(task model.Task, tag model.Tag) bool {
+	for _, attached := range task.Tags {
+		if attached.ID == tag.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether the current user created the tag or owns its workspace
+func canManageTag(p store.TodoStore, r *http.Request, tag model.Tag) bool {
+	user := currentUser(r)
+	return tag.UserID == user.ID || p.GetWorkspaceMember(tag.WorkspaceID, user.ID).Role == model.RoleOwner
+}
+
+// Returns the tag names of the ?tag= query, which can be repeated or separated by commas
+func tagsFromQuery(r *http.Request) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+
+	for _, value := range r.URL.Query()["tag"] {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "" && !seen[name] {
+				seen[name] = true
+				tags = append(tags, name)
+			}
+		}
+	}
+	return tags
+}
@@ -4,46 +4,82 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
-	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
 	"github.com/mpfen/Go-Todo-REST-API/api/store"
 )
 
-// Handler for GET /projects/{projectName}/task/{taskName}
+// Handler for GET /projects/{name}/tasks/{taskName} and GET /tasks/{taskID}
+// The description is rendered as HTML with ?render=html
 func GetTaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectName := vars["projectName"]
-	taskName := vars["taskName"]
-
-	task := p.GetTask(projectName, taskName)
-
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleViewer)
 	if task.Name == "" {
-		sendJSONResponse(w, fmt.Sprintf("No task %v in project %v found", taskName, projectName), http.StatusNotFound)
 		return
-	} else {
-		w.Header().Set("content-type", jsonContentType)
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(task)
 	}
 
+	html, ok := checkRenderOr400(w, r)
+	if !ok {
+		return
+	}
+	if html {
+		task.DescriptionHTML = renderMarkdown(task.Description)
+	}
+
+	// List dependencies in projects the user can view
+	task.BlockedBy = getVisibleTaskReferences(p, r, p.GetBlockers(task))
+	task.Blocks = getVisibleTaskReferences(p, r, p.GetBlockedTasks(task))
+	task.Checklist = p.GetChecklist(task)
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(task)
 }
 
-// Handler for POST /projects/{projectName}/task/{taskName}
+// Handler for POST /projects/{name}/tasks
 func PostTaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
 	// Decode task from request
-	task := decodeTaskFromRequestOr400(w, r)
-
-	vars := mux.Vars(r)
-	projectName := vars["projectName"]
-	taskName := task.Name
+	task, ok := decodeTaskFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
 
 	// Check if project exists and get its id
-	project := checkIfProjectExistsOr404(p, w, projectName)
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleEditor)
+	if project.Name == "" {
+		return
+	}
+
+	// Subtasks are created below their parent
+	task.ParentID = nil
+	createTask(p, w, r, project, task)
+}
+
+// Validates a new task of the project and creates it
+func createTask(p store.TodoStore, w http.ResponseWriter, r *http.Request, project model.Project, task model.Task) {
+	taskName := task.Name
 
 	task.ProjectID = project.ID
+	task.UserID = currentUser(r).ID
+
+	priority, ok := checkPriorityOr400(w, task.Priority)
+	if !ok {
+		return
+	}
+	task.Priority = priority
+
+	task.Recurrence, task.RepeatFrom, ok = checkRecurrenceOr400(w, task.Recurrence, task.RepeatFrom)
+	if !ok {
+		return
+	}
+
+	if !checkEstimateOr400(w, project.EstimateUnit, task.Estimate, task.Remaining) {
+		return
+	}
 
 	// Check if task already exists
-	duplicateTask := p.GetTask(projectName, taskName)
+	duplicateTask := p.GetTask(project, taskName)
 
 	if duplicateTask.Name != "" {
 		sendJSONResponse(w, "A Task with that name already exists for this project", http.StatusBadRequest)
@@ -58,38 +94,86 @@ func PostTaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	sendJSONResponse(w, fmt.Sprintf("Task %v for project %v created", taskName, projectName), http.StatusCreated)
+	created := p.GetTask(project, taskName)
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceTask, ResourceID: created.ID, ProjectID: project.ID}, nil, created)
+
+	sendJSONResponse(w, fmt.Sprintf("Task %v for project %v created", taskName, project.Name), http.StatusCreated)
 }
 
-// Handler for route GET /project/{projectName}/task
+// Handler for route GET /projects/{name}/tasks
+// Sorted by ?sort=priority or ?sort=deadline. Filtered by ?tag= with ?match=any (default) or ?match=all
+// and by ?assignee= with user names or unassigned.
+// Descriptions are rendered as HTML with ?render=html
 func GetAllProjectTasksHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectName := vars["projectName"]
-
 	// Check if projects exists
-	project := checkIfProjectExistsOr404(p, w, projectName)
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleViewer)
+	if project.Name == "" {
+		return
+	}
+
+	filter := model.TaskFilter{Sort: r.URL.Query().Get("sort"), Tags: tagsFromQuery(r), Match: r.URL.Query().Get("match")}
+	filter.Assignees, filter.Unassigned = assigneesFromQuery(r)
+	if filter.Sort != "" && filter.Sort != model.SortPriority && filter.Sort != model.SortDeadline {
+		sendJSONResponse(w, "sort must be priority or deadline", http.StatusBadRequest)
+		return
+	}
+
+	if filter.Match == "" {
+		filter.Match = model.MatchAny
+	}
+	if filter.Match != model.MatchAny && filter.Match != model.MatchAll {
+		sendJSONResponse(w, "match must be any or all", http.StatusBadRequest)
+		return
+	}
+
+	html, ok := checkRenderOr400(w, r)
+	if !ok {
+		return
+	}
+
+	includeFuture, ok := checkIncludeFutureOr400(w, r)
+	if !ok {
+		return
+	}
 
 	// Get all tasks
-	tasks := p.GetAllProjectTasks(project)
+	tasks := p.GetAllProjectTasks(project, filter)
+	if !includeFuture {
+		tasks = hideFutureTasks(tasks)
+	}
 
 	if len(tasks) == 0 {
-		sendJSONResponse(w, fmt.Sprintf("No tasks in project %v found", projectName), http.StatusNotFound)
+		sendJSONResponse(w, fmt.Sprintf("No tasks in project %v found", project.Name), http.StatusNotFound)
 		return
 	} else {
+		if html {
+			renderTaskDescriptions(tasks)
+		}
+
 		w.Header().Set("content-type", jsonContentType)
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(tasks)
 	}
 }
 
-// Handler for route DELETE /projects/{projectName}/task/{taskName}
+// Handler for route DELETE /projects/{name}/tasks/{taskName} and DELETE /tasks/{taskID}
+// Moves the task and its subtasks to the trash
 func DeleteTaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectName := vars["projectName"]
-	taskName := vars["taskName"]
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleOwner)
+	if task.Name == "" {
+		return
+	}
 
-	// Check if tasks exists
-	task := checkIfTasksExistsOr404(p, w, taskName, projectName)
+	// Subtasks are deleted with their parent, the deepest first
+	subtasks := getAllSubtasks(p, task)
+	for i := len(subtasks) - 1; i >= 0; i-- {
+		if err := p.DeleteTask(subtasks[i]); err != nil {
+			sendJSONResponse(w, fmt.Sprintf("Problem deleting Task: %v", err), http.StatusInternalServerError)
+			return
+		}
+		recordAudit(p, r, model.AuditEntry{Action: model.AuditDelete, ResourceType: model.ResourceTask, ResourceID: subtasks[i].ID, ProjectID: subtasks[i].ProjectID}, subtasks[i], nil)
+	}
 
 	// Delete task
 	err := p.DeleteTask(task)
@@ -97,67 +181,232 @@ func DeleteTaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request
 	if err != nil {
 		sendJSONResponse(w, fmt.Sprintf("Problem deleting Task: %v", err), http.StatusInternalServerError)
 	} else {
+		recordAudit(p, r, model.AuditEntry{Action: model.AuditDelete, ResourceType: model.ResourceTask, ResourceID: task.ID, ProjectID: task.ProjectID}, task, nil)
 		sendJSONResponse(w, "Task was successfully deleted", http.StatusOK)
 	}
 }
 
-// Handler for route PUT /projects/{projectName}/task/{taskName}
+// Handler for route PUT /projects/{name}/tasks/{taskName} and PUT /tasks/{taskID}
+// Replaces all writable fields of the task, fields missing in the request are cleared
 func UpdateTaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectName := vars["projectName"]
-	taskName := vars["taskName"]
+	// Check if project and task exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
 
-	// Check if projects exists
-	checkIfProjectExistsOr404(p, w, projectName)
+	// Decode task from request
+	updatedTask, ok := decodeTaskRequestFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
 
-	// Check if tasks exists
-	task := checkIfTasksExistsOr404(p, w, taskName, projectName)
+	if saveTaskUpdate(p, w, r, project, task, updatedTask, model.AuditUpdate) {
+		sendJSONResponse(w, "Task successfully updated", http.StatusOK)
+	}
+}
 
-	// Decode task from request
-	updatedTask := decodeTaskFromRequestOr400(w, r)
+// Handler for route PATCH /projects/{name}/tasks/{taskName} and PATCH /tasks/{taskID}
+// Applies a JSON merge patch, only the fields in the patch are changed
+func PatchTaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	patch, ok := decodeMergePatchFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	updatedTask := taskRequest{}
+	for field := range patch {
+		if !updatedTask.has(field) {
+			sendJSONResponse(w, fmt.Sprintf("Field %v can not be changed", field), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := applyMergePatch(newTaskRequest(task), patch, &updatedTask); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !checkDescriptionOr400(w, updatedTask.Description) {
+		return
+	}
+
+	if saveTaskUpdate(p, w, r, project, task, updatedTask, model.AuditUpdate) {
+		sendJSONResponse(w, "Task successfully updated", http.StatusOK)
+	}
+}
+
+// Validates the new fields of a task and saves them, the change is audited with the action.
+// Returns true if the task was saved, otherwise the error was sent
+func saveTaskUpdate(p store.TodoStore, w http.ResponseWriter, r *http.Request, project model.Project, task model.Task, updatedTask taskRequest, action string) bool {
+	if updatedTask.Name == "" {
+		sendJSONResponse(w, "A task name is required", http.StatusBadRequest)
+		return false
+	}
+
+	priority, ok := checkPriorityOr400(w, updatedTask.Priority)
+	if !ok {
+		return false
+	}
+	updatedTask.Priority = priority
+
+	updatedTask.Recurrence, updatedTask.RepeatFrom, ok = checkRecurrenceOr400(w, updatedTask.Recurrence, updatedTask.RepeatFrom)
+	if !ok {
+		return false
+	}
+
+	if !checkEstimateOr400(w, project.EstimateUnit, updatedTask.Estimate, updatedTask.Remaining) {
+		return false
+	}
+
+	// Check if another task already has the new name
+	if duplicateTask := p.GetTask(project, updatedTask.Name); duplicateTask.Name != "" && duplicateTask.ID != task.ID {
+		sendJSONResponse(w, "A Task with that name already exists for this project", http.StatusBadRequest)
+		return false
+	}
 
 	// Update task
-	task.Name = updatedTask.Name
+	before := task
+	updatedTask.apply(&task)
 	err := p.UpdateTask(task)
 
 	if err != nil {
 		sendJSONResponse(w, "Problem updating task", http.StatusInternalServerError)
-		return
-	} else {
-		sendJSONResponse(w, "Task successfully updated", http.StatusOK)
-		return
+		return false
 	}
+
+	recordAudit(p, r, model.AuditEntry{Action: action, ResourceType: model.ResourceTask, ResourceID: task.ID, ProjectID: task.ProjectID}, before, task)
+	return true
 }
 
-// ComepleteTaskHandler PUT DELETE /projects/{projectName}/task/{taskName}/complete
+// ComepleteTaskHandler PUT DELETE /projects/{name}/tasks/{taskName}/complete and /tasks/{taskID}/complete
 // PUT completes task - DELETE reopens task
+// Tasks with open subtasks are only completed with ?subtasks=complete, which completes the subtasks too.
+// Tasks with open blockers are only completed with ?force=true.
+// Completed occurrences of repeating tasks are renamed after their due date, the next occurrence is created
+// as a new task with the name, tags, assignees and reopened subtasks of the completed one
 func CompleteTaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	projectName := vars["projectName"]
-	taskName := vars["taskName"]
+	// Check if project and task exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
 
-	// Check if projects exists
-	checkIfProjectExistsOr404(p, w, projectName)
+	// Tasks with open blockers can only be completed with force
+	if r.Method == "PUT" && r.URL.Query().Get("force") != "true" {
+		if openBlockers := getOpenBlockers(p, task); len(openBlockers) > 0 {
+			sendJSONResponse(w, fmt.Sprintf("Task is blocked by %d open tasks, complete them first or use ?force=true", len(openBlockers)), http.StatusConflict)
+			return
+		}
+	}
 
-	// Check if tasks exists
-	task := checkIfTasksExistsOr404(p, w, taskName, projectName)
+	// Open subtasks have to be completed first or together with their parent
+	if r.Method == "PUT" {
+		openSubtasks := getOpenSubtasks(p, task)
+
+		if len(openSubtasks) > 0 && r.URL.Query().Get("subtasks") != "complete" {
+			sendJSONResponse(w, fmt.Sprintf("Task has %d open subtasks, complete them first or use ?subtasks=complete", len(openSubtasks)), http.StatusConflict)
+			return
+		}
+
+		for _, subtask := range openSubtasks {
+			before := subtask
+			subtask.CompleteTask()
+
+			if err := p.UpdateTask(subtask); err != nil {
+				sendJSONResponse(w, "Problem upating task", http.StatusInternalServerError)
+				return
+			}
+			recordAudit(p, r, model.AuditEntry{Action: model.AuditComplete, ResourceType: model.ResourceTask, ResourceID: subtask.ID, ProjectID: subtask.ProjectID}, before, subtask)
+
+			if !stopRunningTimers(p, w, r, subtask) {
+				return
+			}
+		}
+
+		// Remaining checklist items are checked on request, otherwise they stay as they are
+		if r.URL.Query().Get("checklist") == "complete" && !setChecklistChecked(p, w, r, task, true) {
+			return
+		}
+	}
 
-	// Complete or reopen and update task
-	var responseText string
+	// Complete or reopen and update task, repeating tasks move on to their next occurrence
+	before := task
+	var responseText, action string
+	next, repeated := task.NextOccurrence(time.Now())
 	if r.Method == "PUT" {
 		task.CompleteTask()
 		responseText = "Task successfully completed"
+		action = model.AuditComplete
 	} else {
 		task.ReopenTask()
 		responseText = "Task successfully reopened"
+		action = model.AuditReopen
+		repeated = false
 	}
 
-	// Update task
-	err := p.UpdateTask(task)
+	// Update task, the completed occurrence of a repeating task makes room for the next one and no longer repeats
+	var err error
+	if repeated {
+		task.Name = completedOccurrenceName(p, project, task)
+		task.Recurrence = ""
+		next, err = p.RepeatTask(task, next)
+		responseText = fmt.Sprintf("Task successfully completed, next occurrence is due %v", next.Deadline.Format(time.RFC3339))
+	} else {
+		err = p.UpdateTask(task)
+	}
 	if err != nil {
 		sendJSONResponse(w, "Problem upating task", http.StatusInternalServerError)
 		return
-	} else {
-		sendJSONResponse(w, responseText, http.StatusOK)
 	}
+	recordAudit(p, r, model.AuditEntry{Action: action, ResourceType: model.ResourceTask, ResourceID: task.ID, ProjectID: task.ProjectID}, before, task)
+
+	// Work on a completed task is over
+	if r.Method == "PUT" && !stopRunningTimers(p, w, r, task) {
+		return
+	}
+
+	// The next occurrence starts with open subtasks
+	if repeated {
+		recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceTask, ResourceID: next.ID, ProjectID: next.ProjectID}, nil, next)
+
+		for _, subtask := range getAllSubtasks(p, next) {
+			if !subtask.Done {
+				continue
+			}
+
+			before := subtask
+			subtask.ReopenTask()
+
+			if err := p.UpdateTask(subtask); err != nil {
+				sendJSONResponse(w, "Problem upating task", http.StatusInternalServerError)
+				return
+			}
+			recordAudit(p, r, model.AuditEntry{Action: model.AuditReopen, ResourceType: model.ResourceTask, ResourceID: subtask.ID, ProjectID: subtask.ProjectID}, before, subtask)
+		}
+	}
+
+	sendJSONResponse(w, responseText, http.StatusOK)
+}
+
+// Returns the name of a completed occurrence of a repeating task, the name of the task followed by
+// its due date or the date of today. A number is added if the project already has a task of that name
+func completedOccurrenceName(p store.TodoStore, project model.Project, task model.Task) string {
+	day := time.Now()
+	if task.Deadline != nil {
+		day = *task.Deadline
+	}
+
+	base := fmt.Sprintf("%v (%v)", task.Name, day.Format("2006-01-02"))
+	name := base
+	for n := 2; p.GetTask(project, name).Name != ""; n++ {
+		name = fmt.Sprintf("%v (%d)", base, n)
+	}
+	return name
 }
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Handler for POST /projects/{name}/tasks/{taskName}/timer/start and POST /tasks/{taskID}/timer/start
+// Every user can only run one timer at a time
+func StartTimerHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	if task.Done {
+		sendJSONResponse(w, "Task is already completed", http.StatusConflict)
+		return
+	}
+
+	user := currentUser(r)
+	if running := p.GetTimeEntries(model.TimeEntryFilter{UserID: user.ID, Running: true}); len(running) > 0 {
+		sendJSONResponse(w, fmt.Sprintf("A timer is already running on task %d, stop it first", running[0].TaskID), http.StatusConflict)
+		return
+	}
+
+	// Timers started at the same time pass the check above, the store keeps only one of them
+	entry, err := p.PostTimeEntry(model.TimeEntry{TaskID: task.ID, ProjectID: project.ID, UserID: user.ID, Start: time.Now()})
+
+	if errors.Is(err, store.ErrTimerRunning) {
+		sendJSONResponse(w, "A timer is already running, stop it first", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceTimeEntry, ResourceID: entry.ID, ProjectID: project.ID}, nil, entry)
+
+	sendJSONResponse(w, fmt.Sprintf("Timer %d started", entry.ID), http.StatusCreated)
+}
+
+// Handler for POST /projects/{name}/tasks/{taskName}/timer/stop and POST /tasks/{taskID}/timer/stop
+// Stops the running timer of the user on the task
+func StopTimerHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	running := p.GetTimeEntries(model.TimeEntryFilter{TaskID: task.ID, UserID: currentUser(r).ID, Running: true})
+	if len(running) == 0 {
+		sendJSONResponse(w, fmt.Sprintf("No running timer on task %v", task.Name), http.StatusConflict)
+		return
+	}
+
+	entry := running[0]
+	before := entry
+	entry.Stop(time.Now())
+
+	if err := p.UpdateTimeEntry(entry); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceTimeEntry, ResourceID: entry.ID, ProjectID: task.ProjectID}, before, entry)
+
+	sendJSONResponse(w, fmt.Sprintf("Timer stopped after %v", time.Duration(entry.Seconds)*time.Second), http.StatusOK)
+}
+
+// Handler for GET /projects/{name}/tasks/{taskName}/time and GET /tasks/{taskID}/time
+// Returns the time entries of the task and their total, filtered by start with ?from= and ?to= (RFC 3339)
+func GetTimeEntriesHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project and task exist
+	_, task := checkIfTaskExistsOr404(p, w, r, model.RoleViewer)
+	if task.Name == "" {
+		return
+	}
+
+	filter, ok := decodeTimeRangeOr400(w, r)
+	if !ok {
+		return
+	}
+	filter.TaskID = task.ID
+
+	report := newTimeReport(filter)
+	report.Entries = p.GetTimeEntries(filter)
+
+	now := time.Now()
+	for i := range report.Entries {
+		report.Entries[i].Measure(now)
+		report.Seconds += report.Entries[i].Seconds
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// Handler for POST /projects/{name}/tasks/{taskName}/time and POST /tasks/{taskID}/time
+// Adds time spent on the task without running a timer
+func PostTimeEntryHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeTimeEntryFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	// Check if project and task exist
+	project, task := checkIfTaskExistsOr404(p, w, r, model.RoleEditor)
+	if task.Name == "" {
+		return
+	}
+
+	// Stored in local time like the entries of timers, so that ranges compare correctly
+	start, end := request.Start.Local(), request.End.Local()
+	entry, err := p.PostTimeEntry(model.TimeEntry{TaskID: task.ID, ProjectID: project.ID, UserID: currentUser(r).ID, Start: start, End: &end, Note: request.Note})
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceTimeEntry, ResourceID: entry.ID, ProjectID: project.ID}, nil, entry)
+
+	sendJSONResponse(w, fmt.Sprintf("Time entry %d created", entry.ID), http.StatusCreated)
+}
+
+// Handler for GET /projects/{name}/time
+// Returns the time spent on the project and on each of its tasks, filtered like the time entries of a task
+func GetProjectTimeHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Check if project exists
+	project := checkIfProjectExistsOr404(p, w, r, model.RoleViewer)
+	if project.Name == "" {
+		return
+	}
+
+	filter, ok := decodeTimeRangeOr400(w, r)
+	if !ok {
+		return
+	}
+	filter.ProjectID = project.ID
+
+	report := newTimeReport(filter)
+	report.Tasks = []model.TaskTime{}
+
+	// Sum up the entries by task in the order of the tasks
+	seconds := map[uint]int64{}
+	now := time.Now()
+	for _, entry := range p.GetTimeEntries(filter) {
+		entry.Measure(now)
+		seconds[entry.TaskID] += entry.Seconds
+		report.Seconds += entry.Seconds
+	}
+
+	for _, task := range p.GetAllProjectTasks(project, model.TaskFilter{}) {
+		if s, ok := seconds[task.ID]; ok {
+			report.Tasks = append(report.Tasks, model.TaskTime{TaskID: task.ID, Name: task.Name, Seconds: s})
+		}
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// Stops all running timers on the task. Returns false and sends an error if a timer could not be stopped
+func stopRunningTimers(p store.TodoStore, w http.ResponseWriter, r *http.Request, task model.Task) bool {
+	now := time.Now()
+
+	for _, entry := range p.GetTimeEntries(model.TimeEntryFilter{TaskID: task.ID, Running: true}) {
+		before := entry
+		entry.Stop(now)
+
+		if err := p.UpdateTimeEntry(entry); err != nil {
+			sendJSONResponse(w, "Problem stopping timer", http.StatusInternalServerError)
+			return false
+		}
+		recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceTimeEntry, ResourceID: entry.ID, ProjectID: task.ProjectID}, before, entry)
+	}
+	return true
+}
+
+// Decodes the range of time entries from the query parameters from and to (RFC 3339) or sends a http.StatusBadRequest
+func decodeTimeRangeOr400(w http.ResponseWriter, r *http.Request) (model.TimeEntryFilter, bool) {
+	query := r.URL.Query()
+	filter := model.TimeEntryFilter{}
+
+	var err error
+	if v := query.Get("from"); v != "" {
+		filter.From, err = time.Parse(time.RFC3339, v)
+	}
+	if v := query.Get("to"); v != "" && err == nil {
+		filter.To, err = time.Parse(time.RFC3339, v)
+	}
+
+	if err != nil {
+		sendJSONResponse(w, "Invalid range: "+err.Error(), http.StatusBadRequest)
+		return filter, false
+	}
+	return filter, true
+}
+
+// Returns an empty report for the range of the filter
+func newTimeReport(filter model.TimeEntryFilter) model.TimeReport {
+	report := model.TimeReport{}
+	if !filter.From.IsZero() {
+		report.From = &filter.From
+	}
+	if !filter.To.IsZero() {
+		report.To = &filter.To
+	}
+	return report
+}
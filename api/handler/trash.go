@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Actor of the audit entries of items purged after the retention period
+const trashActor = "trash"
+
+// Handler for GET /trash
+// Lists the deleted projects and tasks of the projects the user owns, newest first
+func GetTrashHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p.GetTrash(model.TrashFilter{OwnerID: currentUser(r).ID}))
+}
+
+// Handler for POST /trash/projects/{id}/restore
+// Restores the project with its tasks unless another project took its name
+func RestoreProjectHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	project := checkIfTrashedProjectExistsOr404(p, w, r)
+	if project.Name == "" {
+		return
+	}
+
+	if p.GetProject(project.WorkspaceID, project.Name).Name != "" {
+		sendJSONResponse(w, fmt.Sprintf("A project %v already exists, rename it to restore this one", project.Name), http.StatusConflict)
+		return
+	}
+
+	if err := p.RestoreProject(project); err != nil {
+		sendJSONResponse(w, fmt.Sprintf("Problem restoring project: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditRestore, ResourceType: model.ResourceProject, ResourceID: project.ID, ProjectID: project.ID}, nil, project)
+	sendJSONResponse(w, fmt.Sprintf("Project %v restored", project.Name), http.StatusOK)
+}
+
+// Handler for DELETE /trash/projects/{id}
+// Deletes the project permanently with all of its tasks and the files no other attachment uses
+func PurgeProjectHandler(p store.TodoStore, a *AttachmentStorage, w http.ResponseWriter, r *http.Request) {
+	project := checkIfTrashedProjectExistsOr404(p, w, r)
+	if project.Name == "" {
+		return
+	}
+
+	if err := purgeProject(p, a, project); err != nil {
+		sendJSONResponse(w, fmt.Sprintf("Problem purging project: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditPurge, ResourceType: model.ResourceProject, ResourceID: project.ID, ProjectID: project.ID}, project, nil)
+	sendJSONResponse(w, fmt.Sprintf("Project %v purged", project.Name), http.StatusOK)
+}
+
+// Handler for POST /trash/tasks/{id}/restore
+// Restores the task with its deleted subtasks. Subtasks are only restored with their parent
+// and no restored task may have the name of a task in the project
+func RestoreTaskHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	project, task := checkIfTrashedTaskExistsOr404(p, w, r)
+	if task.Name == "" {
+		return
+	}
+
+	if task.ParentID != nil && p.GetTaskByID(*task.ParentID).ID == 0 {
+		sendJSONResponse(w, "The parent task is deleted too, restore it instead", http.StatusConflict)
+		return
+	}
+
+	tasks := append([]model.Task{task}, p.GetTrashedSubtasks(task)...)
+	for _, restored := range tasks {
+		if p.GetTask(project, restored.Name).Name != "" {
+			sendJSONResponse(w, fmt.Sprintf("Project %v already has a task %v, rename it to restore this one", project.Name, restored.Name), http.StatusConflict)
+			return
+		}
+	}
+
+	if err := p.RestoreTasks(tasks); err != nil {
+		sendJSONResponse(w, fmt.Sprintf("Problem restoring task: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	for _, restored := range tasks {
+		recordAudit(p, r, model.AuditEntry{Action: model.AuditRestore, ResourceType: model.ResourceTask, ResourceID: restored.ID, ProjectID: restored.ProjectID}, nil, restored)
+	}
+	sendJSONResponse(w, fmt.Sprintf("Task %v restored", task.Name), http.StatusOK)
+}
+
+// Handler for DELETE /trash/tasks/{id}
+// Deletes the task permanently with its subtasks and the files no other attachment uses
+func PurgeTaskHandler(p store.TodoStore, a *AttachmentStorage, w http.ResponseWriter, r *http.Request) {
+	_, task := checkIfTrashedTaskExistsOr404(p, w, r)
+	if task.Name == "" {
+		return
+	}
+
+	tasks, err := purgeTask(p, a, task)
+	if err != nil {
+		sendJSONResponse(w, fmt.Sprintf("Problem purging task: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	for _, purged := range tasks {
+		recordAudit(p, r, model.AuditEntry{Action: model.AuditPurge, ResourceType: model.ResourceTask, ResourceID: purged.ID, ProjectID: purged.ProjectID}, purged, nil)
+	}
+	sendJSONResponse(w, fmt.Sprintf("Task %v purged", task.Name), http.StatusOK)
+}
+
+// Purges the projects and tasks deleted before the time. Failures are logged and
+// the items are tried again on the next run
+func PurgeExpiredTrash(p store.TodoStore, a *AttachmentStorage, before time.Time) {
+	for _, item := range p.GetTrash(model.TrashFilter{Before: before}) {
+		entry := model.AuditEntry{Actor: trashActor, Action: model.AuditPurge, ResourceType: item.ResourceType, ResourceID: item.ID, ProjectID: item.ProjectID}
+
+		var err error
+		if item.ResourceType == model.ResourceProject {
+			project := p.GetTrashedProject(item.ID)
+			err = purgeProject(p, a, project)
+			entry.Before = marshalAuditState(project)
+		} else {
+			task := p.GetTrashedTask(item.ID)
+			_, err = purgeTask(p, a, task)
+			entry.Before = marshalAuditState(task)
+		}
+
+		if err != nil {
+			log.Printf("could not purge %v %d: %v", item.ResourceType, item.ID, err)
+			continue
+		}
+		if err := p.PostAuditEntry(entry); err != nil {
+			log.Printf("could not write audit entry for %v %v %d: %v", entry.Action, entry.ResourceType, entry.ResourceID, err)
+		}
+	}
+}
+
+// Purges the trash every interval from items deleted longer than the retention ago
+func AutoPurgeTrash(p store.TodoStore, a *AttachmentStorage, retention, interval time.Duration) {
+	for {
+		PurgeExpiredTrash(p, a, time.Now().Add(-retention))
+		time.Sleep(interval)
+	}
+}
+
+func purgeProject(p store.TodoStore, a *AttachmentStorage, project model.Project) error {
+	attachments := p.GetProjectAttachments(project)
+	if err := p.PurgeProject(project); err != nil {
+		return err
+	}
+
+	removeOrphanedFiles(p, a, attachments)
+	return nil
+}
+
+// Returns the purged task and subtasks
+func purgeTask(p store.TodoStore, a *AttachmentStorage, task model.Task) ([]model.Task, error) {
+	tasks := append([]model.Task{task}, p.GetTrashedSubtasks(task)...)
+
+	attachments := []model.Attachment{}
+	for _, purged := range tasks {
+		attachments = append(attachments, p.GetAttachments(purged)...)
+	}
+
+	if err := p.PurgeTasks(tasks); err != nil {
+		return nil, err
+	}
+
+	removeOrphanedFiles(p, a, attachments)
+	return tasks, nil
+}
+
+// Checks if the deleted project exists and is owned by the current user. Returns it or sends a http.StatusNotFound
+func checkIfTrashedProjectExistsOr404(p store.TodoStore, w http.ResponseWriter, r *http.Request) model.Project {
+	id, _ := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	project := p.GetTrashedProject(uint(id))
+
+	if project.ID == 0 || p.GetMembership(project.ID, currentUser(r).ID).Role != model.RoleOwner || !canAccessWorkspace(p, project.WorkspaceID, currentUser(r).ID) {
+		sendJSONResponse(w, "No project with that id in the trash", http.StatusNotFound)
+		return model.Project{}
+	}
+	return project
+}
+
+// Checks if the deleted task exists in a project outside the trash that the current user owns.
+// Returns the project and task or sends a http.StatusNotFound
+func checkIfTrashedTaskExistsOr404(p store.TodoStore, w http.ResponseWriter, r *http.Request) (model.Project, model.Task) {
+	id, _ := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	task := p.GetTrashedTask(uint(id))
+	project := p.GetProjectByID(task.ProjectID)
+
+	if task.ID == 0 || project.ID == 0 || p.GetMembership(project.ID, currentUser(r).ID).Role != model.RoleOwner || !canAccessWorkspace(p, project.WorkspaceID, currentUser(r).ID) {
+		sendJSONResponse(w, "No task with that id in the trash", http.StatusNotFound)
+		return model.Project{}, model.Task{}
+	}
+	return project, task
+}
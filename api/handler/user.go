@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Minimum length of a user password in characters and maximum in bytes, bcrypt rejects longer passwords
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72
+)
+
+// Handler for POST /users
+func PostUserHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	// Decode name and password from request
+	credentials, ok := decodeCredentialsFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	if credentials.Name == "" || len(credentials.Password) < minPasswordLength {
+		sendJSONResponse(w, "A name and a password with at least 8 characters are required", http.StatusBadRequest)
+		return
+	}
+
+	if len(credentials.Password) > maxPasswordLength {
+		sendJSONResponse(w, fmt.Sprintf("Passwords can be at most %d bytes long", maxPasswordLength), http.StatusBadRequest)
+		return
+	}
+
+	// Check if user already exists
+	if p.GetUser(credentials.Name).Name != "" {
+		sendJSONResponse(w, "User with the same name already exists", http.StatusBadRequest)
+		return
+	}
+
+	user := model.User{Name: credentials.Name}
+	if err := user.SetPassword(credentials.Password); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Create new user
+	err := p.PostUser(user)
+
+	if err != nil {
+		sendJSONResponse(w, "User with the same name already exists", http.StatusBadRequest)
+		return
+	}
+
+	// Registration is not authenticated, the new user is the actor
+	created := p.GetUser(user.Name)
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceUser, ResourceID: created.ID, ActorID: created.ID, Actor: created.Name}, nil, created)
+
+	sendJSONResponse(w, "User successfully created", http.StatusCreated)
+}
+
+// Handler for POST /login
+// Returns a bearer token for the authenticated user
+func LoginHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	credentials, ok := decodeCredentialsFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	user := p.GetUser(credentials.Name)
+
+	if user.Name == "" || !user.CheckPassword(credentials.Password) {
+		sendJSONResponse(w, "Invalid name or password", http.StatusUnauthorized)
+		return
+	}
+
+	token, secret, err := model.NewToken(user.ID)
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := p.PostToken(token); err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"token":      secret,
+		"expires_at": token.ExpiresAt,
+	})
+}
+
+// Handler for POST /logout
+// Revokes the login token used for this request. API keys and tokens of an identity provider
+// are not login tokens and are rejected
+func LogoutHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	hash := model.HashToken(bearerToken(r))
+
+	if p.GetUserByToken(hash).ID == 0 {
+		sendJSONResponse(w, "Only login tokens can be logged out, API keys are revoked with DELETE /api-keys/{id}", http.StatusBadRequest)
+		return
+	}
+
+	err := p.DeleteToken(hash)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sendJSONResponse(w, "Successfully logged out", http.StatusOK)
+}
+
+// Handler for GET /me
+func GetCurrentUserHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(currentUser(r))
+}
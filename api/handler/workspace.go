@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Handler for GET /workspaces
+func GetAllWorkspacesHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p.GetAllWorkspaces(currentUser(r).ID))
+}
+
+// Handler for GET /workspaces/{workspace}
+func GetWorkspaceHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	workspace := checkIfWorkspaceExistsOr404(p, w, r, model.RoleViewer)
+	if workspace.Name == "" {
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(workspace)
+}
+
+// Handler for POST /workspaces
+// The creator becomes the owner of the new workspace
+func PostWorkspaceHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	workspace, ok := decodeWorkspaceFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	if workspace.Name == "" {
+		sendJSONResponse(w, "A workspace name is required", http.StatusBadRequest)
+		return
+	}
+
+	workspace.UserID = currentUser(r).ID
+	err := p.PostWorkspace(workspace)
+
+	if err != nil {
+		sendJSONResponse(w, "Workspace with the same name already exists", http.StatusBadRequest)
+		return
+	}
+
+	created := p.GetWorkspace(workspace.Name)
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceWorkspace, ResourceID: created.ID}, nil, created)
+
+	sendJSONResponse(w, "Workspace successfully created", http.StatusCreated)
+}
+
+// Handler for GET /workspaces/{workspace}/members
+func GetWorkspaceMembersHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	workspace := checkIfWorkspaceExistsOr404(p, w, r, model.RoleViewer)
+	if workspace.Name == "" {
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(p.GetWorkspaceMembers(workspace.ID))
+}
+
+// Handler for POST /workspaces/{workspace}/members
+// Invites a user to the workspace with the given role
+func PostWorkspaceMemberHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	workspace := checkIfNonDefaultWorkspaceOr400(p, w, r, model.RoleOwner)
+	if workspace.Name == "" {
+		return
+	}
+
+	request, ok := decodeMembershipFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	if !request.Role.Valid() {
+		sendJSONResponse(w, "Role must be one of owner, editor or viewer", http.StatusBadRequest)
+		return
+	}
+
+	user := checkIfUserExistsOr404(p, w, request.User)
+	if user.Name == "" {
+		return
+	}
+
+	if p.GetWorkspaceMember(workspace.ID, user.ID).Role.Valid() {
+		sendJSONResponse(w, fmt.Sprintf("User %v is already a member of workspace %v", user.Name, workspace.Name), http.StatusBadRequest)
+		return
+	}
+
+	err := p.PostWorkspaceMember(model.WorkspaceMember{WorkspaceID: workspace.ID, UserID: user.ID, Role: request.Role})
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	created := p.GetWorkspaceMember(workspace.ID, user.ID)
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditCreate, ResourceType: model.ResourceWorkspaceMember, ResourceID: created.ID}, nil, created)
+
+	sendJSONResponse(w, fmt.Sprintf("User %v added to workspace %v", user.Name, workspace.Name), http.StatusCreated)
+}
+
+// Handler for PUT /workspaces/{workspace}/members/{userName}
+// Changes the role of a member
+func UpdateWorkspaceMemberHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	workspace := checkIfNonDefaultWorkspaceOr400(p, w, r, model.RoleOwner)
+	if workspace.Name == "" {
+		return
+	}
+
+	member := checkIfWorkspaceMemberExistsOr404(p, w, workspace, vars["userName"])
+	if member.ID == 0 {
+		return
+	}
+
+	request, ok := decodeMembershipFromRequestOr400(w, r)
+	if !ok {
+		return
+	}
+
+	if !request.Role.Valid() {
+		sendJSONResponse(w, "Role must be one of owner, editor or viewer", http.StatusBadRequest)
+		return
+	}
+
+	if member.Role == model.RoleOwner && request.Role != model.RoleOwner && isLastWorkspaceOwner(p, workspace) {
+		sendJSONResponse(w, "A workspace needs at least one owner", http.StatusBadRequest)
+		return
+	}
+
+	before := member
+	member.Role = request.Role
+	err := p.UpdateWorkspaceMember(member)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditUpdate, ResourceType: model.ResourceWorkspaceMember, ResourceID: member.ID}, before, member)
+
+	sendJSONResponse(w, "Member successfully updated", http.StatusOK)
+}
+
+// Handler for DELETE /workspaces/{workspace}/members/{userName}
+// Owners can remove any member, every member can leave a workspace
+func DeleteWorkspaceMemberHandler(p store.TodoStore, w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userName := vars["userName"]
+
+	role := model.RoleOwner
+	if userName == currentUser(r).Name {
+		role = model.RoleViewer
+	}
+
+	workspace := checkIfNonDefaultWorkspaceOr400(p, w, r, role)
+	if workspace.Name == "" {
+		return
+	}
+
+	member := checkIfWorkspaceMemberExistsOr404(p, w, workspace, userName)
+	if member.ID == 0 {
+		return
+	}
+
+	if member.Role == model.RoleOwner && isLastWorkspaceOwner(p, workspace) {
+		sendJSONResponse(w, "A workspace needs at least one owner", http.StatusBadRequest)
+		return
+	}
+
+	err := p.DeleteWorkspaceMember(member)
+
+	if err != nil {
+		sendJSONResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(p, r, model.AuditEntry{Action: model.AuditDelete, ResourceType: model.ResourceWorkspaceMember, ResourceID: member.ID}, member, nil)
+
+	sendJSONResponse(w, "Member successfully removed", http.StatusOK)
+}
+
+// Checks the workspace like checkIfWorkspaceExistsOr404 and sends a 400 message
+// for the default workspace, which is open to every user and has no members
+func checkIfNonDefaultWorkspaceOr400(p store.TodoStore, w http.ResponseWriter, r *http.Request, role model.Role) model.Workspace {
+	workspace := checkIfWorkspaceExistsOr404(p, w, r, model.RoleViewer)
+	if workspace.Name == "" {
+		return model.Workspace{}
+	}
+
+	if workspace.IsDefault() {
+		sendJSONResponse(w, "Members of the default workspace can not be changed", http.StatusBadRequest)
+		return model.Workspace{}
+	}
+
+	return checkIfWorkspaceExistsOr404(p, w, r, role)
+}
+
+// Reports whether the workspace has only a single owner left
+func isLastWorkspaceOwner(p store.TodoStore, workspace model.Workspace) bool {
+	owners := 0
+	for _, member := range p.GetWorkspaceMembers(workspace.ID) {
+		if member.Role == model.RoleOwner {
+			owners++
+		}
+	}
+	return owners <= 1
+}
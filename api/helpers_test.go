@@ -2,10 +2,17 @@ package api_test
 
 import (
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	api "github.com/mpfen/Go-Todo-REST-API/api"
 	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
 )
 
 type stubTask struct {
@@ -16,17 +23,36 @@ type stubTask struct {
 	ProjectID string
 }
 
+// Token accepted by the stub for the user returned by stubUser
+const stubToken = "stubtoken"
+
+// ID of the default workspace in the stub
+const stubWorkspaceID = 1
+
+// The user every project in the stub belongs to
+func stubUser() model.User {
+	user := model.User{Name: "alice"}
+	user.ID = 1
+	return user
+}
+
 // DB store stub for testing
 type StubTodoStore struct {
 	Projects map[string]bool
 	Tasks    []stubTask
+	Users    map[string]model.User
+	Tokens   map[string]uint
+	Audit    []model.AuditEntry
 }
 
-// Creates a makeshift project struct to comply with TodoStore interface
-func (s *StubTodoStore) GetProject(name string) model.Project {
+// Creates a makeshift project struct to comply with TodoStore interface.
+// All projects of the stub are in the default workspace
+func (s *StubTodoStore) GetProject(workspaceID uint, name string) model.Project {
 	project := model.Project{}
-	if _, exists := s.Projects[name]; exists {
+	if _, exists := s.Projects[name]; exists && workspaceID == stubWorkspaceID {
 		project.Name = name
+		project.UserID = stubUser().ID
+		project.WorkspaceID = workspaceID
 		return project
 	} else {
 		return project
@@ -34,29 +60,33 @@ func (s *StubTodoStore) GetProject(name string) model.Project {
 }
 
 // Creates a new project
-func (s *StubTodoStore) PostProject(name string) error {
-	if _, exists := s.Projects[name]; exists {
+func (s *StubTodoStore) PostProject(project model.Project) error {
+	if _, exists := s.Projects[project.Name]; exists {
 		return errors.New("project already created")
 	} else {
-		s.Projects[name] = false
+		s.Projects[project.Name] = false
 		return nil
 	}
 }
 
 // Returns an array of all projects
-func (s *StubTodoStore) GetAllProjects() []model.Project {
+func (s *StubTodoStore) GetAllProjects(workspaceID, userID uint) []model.Project {
 	var projects []model.Project
 
+	if userID != stubUser().ID || workspaceID != stubWorkspaceID {
+		return projects
+	}
+
 	for key := range s.Projects {
-		projects = append(projects, model.Project{Name: key})
+		projects = append(projects, model.Project{Name: key, UserID: userID, WorkspaceID: workspaceID})
 	}
 
 	return projects
 }
 
 // Deletes a project from store
-func (s *StubTodoStore) DeleteProject(name string) error {
-	delete(s.Projects, name)
+func (s *StubTodoStore) DeleteProject(project model.Project) error {
+	delete(s.Projects, project.Name)
 	return nil
 }
 
@@ -67,15 +97,54 @@ func (s *StubTodoStore) UpdateProject(project model.Project) error {
 }
 
 // Gets Task from store
-func (s *StubTodoStore) GetTask(projectID, taskName string) model.Task {
+func (s *StubTodoStore) GetTask(project model.Project, taskName string) model.Task {
 	for _, t := range s.Tasks {
-		if t.Name == taskName && t.ProjectID == projectID {
+		if t.Name == taskName && t.ProjectID == project.Name {
 			return wrapStubTask(taskName)
 		}
 	}
 	return model.Task{}
 }
 
+// Tasks and projects have no ids in the stub
+func (s *StubTodoStore) GetTaskByID(id uint) model.Task {
+	return model.Task{}
+}
+
+func (s *StubTodoStore) GetProjectByID(id uint) model.Project {
+	return model.Project{}
+}
+
+// Tasks in the stub have no subtasks
+func (s *StubTodoStore) GetSubtasks(task model.Task) []model.Task {
+	return []model.Task{}
+}
+
+// Tasks in the stub have no dependencies
+func (s *StubTodoStore) GetBlockers(task model.Task) []model.Task {
+	return []model.Task{}
+}
+
+func (s *StubTodoStore) GetBlockedTasks(task model.Task) []model.Task {
+	return []model.Task{}
+}
+
+func (s *StubTodoStore) GetBlockerIDs(taskID uint) []uint {
+	return []uint{}
+}
+
+func (s *StubTodoStore) GetTaskDependency(taskID, blockerID uint) model.TaskDependency {
+	return model.TaskDependency{}
+}
+
+func (s *StubTodoStore) PostTaskDependency(dependency model.TaskDependency) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) DeleteTaskDependency(dependency model.TaskDependency) error {
+	return errors.New("not supported by stub")
+}
+
 // Create task in store
 func (s *StubTodoStore) PostTask(task model.Task) error {
 	newTask := stubTask{Name: task.Name}
@@ -85,7 +154,7 @@ func (s *StubTodoStore) PostTask(task model.Task) error {
 }
 
 // Return all tasks of a project
-func (s *StubTodoStore) GetAllProjectTasks(project model.Project) []model.Task {
+func (s *StubTodoStore) GetAllProjectTasks(project model.Project, filter model.TaskFilter) []model.Task {
 	tasks := []model.Task{}
 
 	for _, t := range s.Tasks {
@@ -123,6 +192,382 @@ func (s *StubTodoStore) UpdateTask(task model.Task) error {
 	return nil
 }
 
+// The stub keeps no positions
+func (s *StubTodoStore) GetAdjacentTaskPosition(task model.Task, after bool) string {
+	return ""
+}
+
+func (s *StubTodoStore) GetAdjacentProjectPosition(project model.Project, after bool) string {
+	return ""
+}
+
+// Tasks in the stub can not be moved
+func (s *StubTodoStore) MoveTasks(tasks []model.Task, project model.Project) error {
+	return errors.New("not supported by stub")
+}
+
+// Gets user from store
+func (s *StubTodoStore) GetUser(name string) model.User {
+	return s.Users[name]
+}
+
+// Creates a user in store
+func (s *StubTodoStore) PostUser(user model.User) error {
+	if s.Users == nil {
+		s.Users = map[string]model.User{}
+	}
+	if _, exists := s.Users[user.Name]; exists {
+		return errors.New("user already created")
+	}
+
+	user.ID = uint(len(s.Users) + 2)
+	s.Users[user.Name] = user
+	return nil
+}
+
+// Stores a login token
+func (s *StubTodoStore) PostToken(token model.Token) error {
+	if s.Tokens == nil {
+		s.Tokens = map[string]uint{}
+	}
+	s.Tokens[token.Hash] = token.UserID
+	return nil
+}
+
+// Deletes a login token
+func (s *StubTodoStore) DeleteToken(hash string) error {
+	delete(s.Tokens, hash)
+	return nil
+}
+
+// Returns the user a token belongs to
+func (s *StubTodoStore) GetUserByToken(hash string) model.User {
+	if hash == model.HashToken(stubToken) {
+		return stubUser()
+	}
+
+	userID, exists := s.Tokens[hash]
+	if !exists {
+		return model.User{}
+	}
+
+	for _, user := range s.Users {
+		if user.ID == userID {
+			return user
+		}
+	}
+	return model.User{}
+}
+
+// The stub user owns every project, other users are no members
+func (s *StubTodoStore) GetMembership(projectID, userID uint) model.Membership {
+	if userID != stubUser().ID {
+		return model.Membership{}
+	}
+
+	membership := model.Membership{ProjectID: projectID, UserID: userID, User: stubUser(), Role: model.RoleOwner}
+	membership.ID = 1
+	return membership
+}
+
+// Returns the stub user as the only member
+func (s *StubTodoStore) GetProjectMembers(projectID uint) []model.Membership {
+	return []model.Membership{s.GetMembership(projectID, stubUser().ID)}
+}
+
+// Memberships can not be changed in the stub
+func (s *StubTodoStore) PostMembership(membership model.Membership) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) UpdateMembership(membership model.Membership) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) DeleteMembership(membership model.Membership) error {
+	return errors.New("not supported by stub")
+}
+
+// Returns the stub user for its ID
+func (s *StubTodoStore) GetUserByID(id uint) model.User {
+	if id == stubUser().ID {
+		return stubUser()
+	}
+
+	for _, user := range s.Users {
+		if user.ID == id {
+			return user
+		}
+	}
+	return model.User{}
+}
+
+// Users of an identity provider are not stored in the stub
+func (s *StubTodoStore) GetUserBySubject(subject string) model.User {
+	return model.User{}
+}
+
+// API keys are not stored in the stub
+func (s *StubTodoStore) GetAPIKey(hash string) model.APIKey {
+	return model.APIKey{}
+}
+
+func (s *StubTodoStore) GetAPIKeyByID(id uint) model.APIKey {
+	return model.APIKey{}
+}
+
+func (s *StubTodoStore) GetAllAPIKeys(userID uint) []model.APIKey {
+	return []model.APIKey{}
+}
+
+func (s *StubTodoStore) PostAPIKey(key model.APIKey) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) UpdateAPIKey(key model.APIKey) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) MarkAPIKeyUsed(key model.APIKey) error {
+	return nil
+}
+
+// Appends an audit entry to the store
+func (s *StubTodoStore) PostAuditEntry(entry model.AuditEntry) error {
+	s.Audit = append(s.Audit, entry)
+	return nil
+}
+
+// Returns all audit entries, filters are ignored
+func (s *StubTodoStore) GetAuditEntries(filter model.AuditFilter) []model.AuditEntry {
+	return s.Audit
+}
+
+// The stub only knows the default workspace
+func (s *StubTodoStore) GetWorkspace(name string) model.Workspace {
+	if name != model.DefaultWorkspaceName {
+		return model.Workspace{}
+	}
+
+	workspace := model.Workspace{Name: name}
+	workspace.ID = stubWorkspaceID
+	return workspace
+}
+
+func (s *StubTodoStore) GetAllWorkspaces(userID uint) []model.Workspace {
+	return []model.Workspace{s.GetWorkspace(model.DefaultWorkspaceName)}
+}
+
+func (s *StubTodoStore) PostWorkspace(workspace model.Workspace) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) GetWorkspaceMember(workspaceID, userID uint) model.WorkspaceMember {
+	return model.WorkspaceMember{}
+}
+
+func (s *StubTodoStore) GetWorkspaceMembers(workspaceID uint) []model.WorkspaceMember {
+	return []model.WorkspaceMember{}
+}
+
+func (s *StubTodoStore) PostWorkspaceMember(member model.WorkspaceMember) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) UpdateWorkspaceMember(member model.WorkspaceMember) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) DeleteWorkspaceMember(member model.WorkspaceMember) error {
+	return errors.New("not supported by stub")
+}
+
+// The stub keeps no revisions
+func (s *StubTodoStore) GetRevisions(resourceType string, resourceID uint) []model.Revision {
+	return []model.Revision{}
+}
+
+func (s *StubTodoStore) GetRevision(resourceType string, resourceID uint, number int) model.Revision {
+	return model.Revision{}
+}
+
+// Tasks in the stub do not repeat
+func (s *StubTodoStore) RepeatTask(completed model.Task, next model.Task) (model.Task, error) {
+	return model.Task{}, errors.New("not supported by stub")
+}
+
+// The stub has no estimates
+func (s *StubTodoStore) CountEstimatedTasks(project model.Project) int64 {
+	return 0
+}
+
+// The stub has no tags
+func (s *StubTodoStore) GetTag(workspaceID uint, name string) model.Tag {
+	return model.Tag{}
+}
+
+func (s *StubTodoStore) GetAllTags(workspaceID uint) []model.Tag {
+	return []model.Tag{}
+}
+
+func (s *StubTodoStore) PostTag(tag model.Tag) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) UpdateTag(tag model.Tag) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) DeleteTag(tag model.Tag) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) AddTaskTag(task model.Task, tag model.Tag) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) DeleteTaskTag(task model.Task, tag model.Tag) error {
+	return errors.New("not supported by stub")
+}
+
+// The stub deletes permanently and has no trash
+func (s *StubTodoStore) GetTrash(filter model.TrashFilter) []model.TrashItem {
+	return []model.TrashItem{}
+}
+
+func (s *StubTodoStore) GetTrashedProject(id uint) model.Project {
+	return model.Project{}
+}
+
+func (s *StubTodoStore) RestoreProject(project model.Project) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) PurgeProject(project model.Project) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) GetTrashedTask(id uint) model.Task {
+	return model.Task{}
+}
+
+func (s *StubTodoStore) GetTrashedSubtasks(task model.Task) []model.Task {
+	return []model.Task{}
+}
+
+func (s *StubTodoStore) RestoreTasks(tasks []model.Task) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) PurgeTasks(tasks []model.Task) error {
+	return errors.New("not supported by stub")
+}
+
+// The stub has no assignees
+func (s *StubTodoStore) AddTaskAssignee(task model.Task, user model.User) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) DeleteTaskAssignee(task model.Task, user model.User) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) GetAssignedTasks(userID uint) []model.Task {
+	return []model.Task{}
+}
+
+// The stub has no comments
+func (s *StubTodoStore) GetComment(id uint) model.Comment {
+	return model.Comment{}
+}
+
+func (s *StubTodoStore) GetComments(task model.Task, filter model.CommentFilter) []model.Comment {
+	return []model.Comment{}
+}
+
+func (s *StubTodoStore) GetCommentReplies(task model.Task) []model.Comment {
+	return []model.Comment{}
+}
+
+func (s *StubTodoStore) PostComment(comment model.Comment) (model.Comment, error) {
+	return model.Comment{}, errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) UpdateComment(comment model.Comment) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) DeleteComment(comment model.Comment) ([]model.Comment, error) {
+	return nil, errors.New("not supported by stub")
+}
+
+// The stub has no attachments
+func (s *StubTodoStore) GetAttachment(id uint) model.Attachment {
+	return model.Attachment{}
+}
+
+func (s *StubTodoStore) GetAttachments(task model.Task) []model.Attachment {
+	return []model.Attachment{}
+}
+
+func (s *StubTodoStore) GetProjectAttachments(project model.Project) []model.Attachment {
+	return []model.Attachment{}
+}
+
+func (s *StubTodoStore) GetProjectAttachmentSize(project model.Project) int64 {
+	return 0
+}
+
+func (s *StubTodoStore) CountAttachments(hash string) int64 {
+	return 0
+}
+
+func (s *StubTodoStore) PostAttachment(attachment model.Attachment) (model.Attachment, error) {
+	return model.Attachment{}, errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) DeleteAttachment(attachment model.Attachment) error {
+	return errors.New("not supported by stub")
+}
+
+// The stub has no checklists
+func (s *StubTodoStore) GetChecklistItem(id uint) model.ChecklistItem {
+	return model.ChecklistItem{}
+}
+
+func (s *StubTodoStore) GetChecklist(task model.Task) []model.ChecklistItem {
+	return []model.ChecklistItem{}
+}
+
+func (s *StubTodoStore) PostChecklistItem(item model.ChecklistItem) (model.ChecklistItem, error) {
+	return model.ChecklistItem{}, errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) UpdateChecklistItem(item model.ChecklistItem) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) UpdateChecklist(items []model.ChecklistItem) error {
+	return errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) DeleteChecklistItem(item model.ChecklistItem) error {
+	return errors.New("not supported by stub")
+}
+
+// The stub has no time entries
+func (s *StubTodoStore) GetTimeEntries(filter model.TimeEntryFilter) []model.TimeEntry {
+	return []model.TimeEntry{}
+}
+
+func (s *StubTodoStore) PostTimeEntry(entry model.TimeEntry) (model.TimeEntry, error) {
+	return model.TimeEntry{}, errors.New("not supported by stub")
+}
+
+func (s *StubTodoStore) UpdateTimeEntry(entry model.TimeEntry) error {
+	return errors.New("not supported by stub")
+}
+
 // to comply with interface
 func wrapStubTask(taskName string) model.Task {
 	modelTask := model.Task{}
@@ -130,6 +575,55 @@ func wrapStubTask(taskName string) model.Task {
 	return modelTask
 }
 
+// Creates a request authenticated with the stub token
+func newAuthenticatedRequest(method, url string, body io.Reader) *http.Request {
+	return newRequestWithToken(method, url, stubToken, body)
+}
+
+// Creates a request with a bearer token
+func newRequestWithToken(method, url, token string, body io.Reader) *http.Request {
+	request, _ := http.NewRequest(method, url, body)
+	request.Header.Set("Authorization", "Bearer "+token)
+	return request
+}
+
+// Sends the request to the server and returns the recorded response
+func serveRequest(server *api.TodoStore, request *http.Request) *httptest.ResponseRecorder {
+	response := httptest.NewRecorder()
+
+	server.Router.ServeHTTP(response, request)
+	return response
+}
+
+// Sends a request with a bearer token and the body to the server and returns the recorded response
+func serveWithToken(server *api.TodoStore, token, method, url, body string) *httptest.ResponseRecorder {
+	return serveRequest(server, newRequestWithToken(method, url, token, strings.NewReader(body)))
+}
+
+// Creates a TodoStore backed by a new database in a temporary directory
+func setUpDatabaseServer(t *testing.T) (*api.TodoStore, *store.Database) {
+	t.Helper()
+
+	db := store.NewDatabaseConnection(filepath.Join(t.TempDir(), "test.db"))
+	return api.NewTodoStore(db), db
+}
+
+// Creates a user in the database and returns a valid token for it
+func createTestUser(t *testing.T, db *store.Database, name string) string {
+	t.Helper()
+
+	err := db.PostUser(model.User{Name: name})
+	assertError(t, "create test user", err)
+
+	token, secret, err := model.NewToken(db.GetUser(name).ID)
+	assertError(t, "create test token", err)
+
+	err = db.PostToken(token)
+	assertError(t, "store test token", err)
+
+	return secret
+}
+
 // common assert functions
 func assertResponseBody(t testing.TB, got, want string) {
 	t.Helper()
@@ -0,0 +1,113 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for routes /projects/{projectID} and /tasks/{taskID}
+func TestIDRoutes(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", makeNewPostProjectBody(t, "homework").String())
+	serveWithToken(server, alice, http.MethodPost, "/projects/homework/tasks", makeNewPostTaskBody(t, "math/algebra", "homework").String())
+
+	workspace := db.GetWorkspace(model.DefaultWorkspaceName)
+	project := db.GetProject(workspace.ID, "homework")
+	task := db.GetTask(project, "math/algebra")
+	taskURL := fmt.Sprintf("/tasks/%d", task.ID)
+
+	t.Run("Get a task with a slash in its name by id", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodGet, taskURL, "")
+
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		var got model.Task
+		json.NewDecoder(response.Body).Decode(&got)
+		assertResponseBody(t, got.Name, "math/algebra")
+	})
+
+	t.Run("Task URL survives a rename", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, taskURL, makeNewPostTaskBody(t, "algebra", "homework").String())
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodGet, taskURL, "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		var got model.Task
+		json.NewDecoder(response.Body).Decode(&got)
+		assertResponseBody(t, got.Name, "algebra")
+	})
+
+	t.Run("Complete a task by id", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, taskURL+"/complete", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if !db.GetTaskByID(task.ID).Done {
+			t.Error("task was not completed")
+		}
+	})
+
+	t.Run("Get a project and its tasks by id", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodGet, fmt.Sprintf("/projects/%d", project.ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodGet, fmt.Sprintf("/projects/%d/tasks/algebra", project.ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("Non members do not see projects and tasks by id", func(t *testing.T) {
+		response := serveWithToken(server, bob, http.MethodGet, fmt.Sprintf("/projects/%d", project.ID), "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+
+		response = serveWithToken(server, bob, http.MethodGet, taskURL, "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("Delete a task by id", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodDelete, taskURL, "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodGet, taskURL, "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+// Tests for ids and names the id routes do not accept
+func TestIDRouteValidation(t *testing.T) {
+	server, _ := setUpProjectTests()
+
+	t.Run("Unknown ids", func(t *testing.T) {
+		for _, url := range []string{"/tasks/999", "/projects/999"} {
+			request := newAuthenticatedRequest(http.MethodGet, url, nil)
+			response := httptest.NewRecorder()
+
+			server.Router.ServeHTTP(response, request)
+
+			assertResponseStatus(t, response.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("Numeric project names are rejected", func(t *testing.T) {
+		request := newAuthenticatedRequest(http.MethodPost, "/projects", makeNewPostProjectBody(t, "2021"))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		request = newAuthenticatedRequest(http.MethodPut, "/projects/homework", makeNewPostProjectBody(t, "2021"))
+		response = httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
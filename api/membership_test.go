@@ -0,0 +1,175 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/mpfen/Go-Todo-REST-API/api"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Sets up project team owned by alice with bob as viewer and carol as editor
+func setUpMembershipTests(t *testing.T) (server *api.TodoStore, tokens map[string]string) {
+	server, db := setUpDatabaseServer(t)
+
+	tokens = map[string]string{}
+	for _, name := range []string{"alice", "bob", "carol", "dave"} {
+		tokens[name] = createTestUser(t, db, name)
+	}
+
+	request := newRequestWithToken(http.MethodPost, "/projects", tokens["alice"], makeNewPostProjectBody(t, "team"))
+	server.Router.ServeHTTP(httptest.NewRecorder(), request)
+
+	for name, role := range map[string]model.Role{"bob": model.RoleViewer, "carol": model.RoleEditor} {
+		request := newRequestWithToken(http.MethodPost, "/projects/team/members", tokens["alice"], makeNewMembershipBody(t, name, role))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+	}
+
+	return server, tokens
+}
+
+// Tests for the role checks of project and task routes
+func TestProjectRoles(t *testing.T) {
+	server, tokens := setUpMembershipTests(t)
+
+	cases := []struct {
+		name   string
+		user   string
+		method string
+		url    string
+		body   func() *bytes.Buffer
+		want   int
+	}{
+		{"viewer can get the project", "bob", http.MethodGet, "/projects/team", nil, http.StatusOK},
+		{"viewer can not create tasks", "bob", http.MethodPost, "/projects/team/tasks", func() *bytes.Buffer { return makeNewPostTaskBody(t, "deploy", "team") }, http.StatusForbidden},
+		{"editor can create tasks", "carol", http.MethodPost, "/projects/team/tasks", func() *bytes.Buffer { return makeNewPostTaskBody(t, "deploy", "team") }, http.StatusCreated},
+		{"viewer can get tasks", "bob", http.MethodGet, "/projects/team/tasks/deploy", nil, http.StatusOK},
+		{"viewer can not complete tasks", "bob", http.MethodPut, "/projects/team/tasks/deploy/complete", nil, http.StatusForbidden},
+		{"editor can complete tasks", "carol", http.MethodPut, "/projects/team/tasks/deploy/complete", nil, http.StatusOK},
+		{"editor can not archive the project", "carol", http.MethodPut, "/projects/team/archive", nil, http.StatusForbidden},
+		{"editor can not delete the project", "carol", http.MethodDelete, "/projects/team", nil, http.StatusForbidden},
+		{"non member does not see the project", "dave", http.MethodGet, "/projects/team", nil, http.StatusNotFound},
+		{"owner can archive the project", "alice", http.MethodPut, "/projects/team/archive", nil, http.StatusOK},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var request *http.Request
+			if c.body != nil {
+				request = newRequestWithToken(c.method, c.url, tokens[c.user], c.body())
+			} else {
+				request = newRequestWithToken(c.method, c.url, tokens[c.user], nil)
+			}
+			response := httptest.NewRecorder()
+
+			server.Router.ServeHTTP(response, request)
+
+			assertResponseStatus(t, response.Code, c.want)
+		})
+	}
+
+	t.Run("Shared projects are listed for members", func(t *testing.T) {
+		request := newRequestWithToken(http.MethodGet, "/projects", tokens["bob"], nil)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		projects := decodeAllProjectsFromResponse(t, response.Body)
+		if len(projects) != 1 || projects[0].Name != "team" {
+			t.Errorf("got %v, want project team", projects)
+		}
+	})
+}
+
+// Tests for routes /projects/{name}/members
+func TestProjectMembers(t *testing.T) {
+	server, tokens := setUpMembershipTests(t)
+
+	t.Run("List members", func(t *testing.T) {
+		request := newRequestWithToken(http.MethodGet, "/projects/team/members", tokens["bob"], nil)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		var members []model.Membership
+		json.NewDecoder(response.Body).Decode(&members)
+
+		assertResponseStatus(t, response.Code, http.StatusOK)
+		if len(members) != 3 {
+			t.Errorf("got %d members, want 3", len(members))
+		}
+	})
+
+	t.Run("Editor can not invite members", func(t *testing.T) {
+		request := newRequestWithToken(http.MethodPost, "/projects/team/members", tokens["carol"], makeNewMembershipBody(t, "dave", model.RoleViewer))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusForbidden)
+	})
+
+	t.Run("Promote viewer to editor", func(t *testing.T) {
+		request := newRequestWithToken(http.MethodPut, "/projects/team/members/bob", tokens["alice"], makeNewMembershipBody(t, "", model.RoleEditor))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		request = newRequestWithToken(http.MethodPost, "/projects/team/tasks", tokens["bob"], makeNewPostTaskBody(t, "review", "team"))
+		response = httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+	})
+
+	t.Run("Invalid roles are rejected", func(t *testing.T) {
+		request := newRequestWithToken(http.MethodPut, "/projects/team/members/bob", tokens["alice"], makeNewMembershipBody(t, "", "admin"))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Last owner can not leave", func(t *testing.T) {
+		request := newRequestWithToken(http.MethodDelete, "/projects/team/members/alice", tokens["alice"], nil)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Remove member", func(t *testing.T) {
+		request := newRequestWithToken(http.MethodDelete, "/projects/team/members/carol", tokens["alice"], nil)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		request = newRequestWithToken(http.MethodGet, "/projects/team", tokens["carol"], nil)
+		response = httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+// makes a new json request body for POST /projects/{name}/members
+func makeNewMembershipBody(t *testing.T, user string, role model.Role) *bytes.Buffer {
+	requestBody, err := json.Marshal(map[string]string{
+		"user": user,
+		"role": string(role),
+	})
+
+	if err != nil {
+		t.Errorf("Failed to make requestBody: %s", err)
+	}
+
+	return bytes.NewBuffer(requestBody)
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Prefix of every plaintext API key. Used to tell API keys and login tokens apart
+const APIKeyPrefix = "todo_"
+
+// Number of characters of a plaintext key stored to recognize it later
+const apiKeyPrefixLength = 12
+
+// Permission granted to an API key
+type Scope string
+
+const (
+	ScopeProjectsRead  Scope = "projects:read"
+	ScopeProjectsWrite Scope = "projects:write"
+	ScopeTasksRead     Scope = "tasks:read"
+	ScopeTasksWrite    Scope = "tasks:write"
+	ScopeAdmin         Scope = "admin"
+)
+
+// Reports whether the scope is one of the known scopes
+func (s Scope) Valid() bool {
+	switch s {
+	case ScopeProjectsRead, ScopeProjectsWrite, ScopeTasksRead, ScopeTasksWrite, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// List of scopes stored as a space separated string
+type Scopes []Scope
+
+func (s Scopes) Value() (driver.Value, error) {
+	parts := make([]string, len(s))
+	for i, scope := range s {
+		parts[i] = string(scope)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+func (s *Scopes) Scan(value interface{}) error {
+	var str string
+	switch v := value.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	case nil:
+		str = ""
+	default:
+		return fmt.Errorf("can not scan %T into Scopes", value)
+	}
+
+	*s = Scopes{}
+	for _, part := range strings.Fields(str) {
+		*s = append(*s, Scope(part))
+	}
+	return nil
+}
+
+func (Scopes) GormDataType() string {
+	return "string"
+}
+
+// Personal API key for scripts. Only the sha256 hash of the key is stored
+type APIKey struct {
+	gorm.Model
+	UserID     uint       `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-" gorm:"unique"`
+	Scopes     Scopes     `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// Creates a new API key. Returns the key that has to be stored
+// and the plaintext key that is handed to the client once
+func NewAPIKey(userID uint, name string, scopes Scopes, expiresAt time.Time) (APIKey, string, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return APIKey{}, "", err
+	}
+	secret = APIKeyPrefix + secret
+
+	key := APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    secret[:apiKeyPrefixLength],
+		Hash:      HashToken(secret),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+
+	return key, secret, nil
+}
+
+// Reports whether the key is neither revoked nor expired
+func (k *APIKey) Active() bool {
+	return k.ID != 0 && k.RevokedAt == nil && time.Now().Before(k.ExpiresAt)
+}
+
+// Reports whether the key grants the scope. The admin scope grants every scope
+func (k *APIKey) Allows(scope Scope) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *APIKey) Revoke() {
+	now := time.Now()
+	k.RevokedAt = &now
+}
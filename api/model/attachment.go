@@ -0,0 +1,16 @@
+package model
+
+import "gorm.io/gorm"
+
+// File attached to a task. The content is stored on disk under its SHA-256 hash,
+// attachments with the same content share the stored file
+type Attachment struct {
+	gorm.Model
+	TaskID      uint   `json:"task_id" gorm:"index"`
+	ProjectID   uint   `json:"project_id" gorm:"index"`
+	UserID      uint   `json:"user_id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Hash        string `json:"sha256" gorm:"index"`
+}
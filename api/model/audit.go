@@ -0,0 +1,107 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditCreate    = "create"
+	AuditUpdate    = "update"
+	AuditRename    = "rename"
+	AuditDelete    = "delete"
+	AuditArchive   = "archive"
+	AuditUnarchive = "unarchive"
+	AuditComplete  = "complete"
+	AuditReopen    = "reopen"
+	AuditRevoke    = "revoke"
+	AuditMove      = "move"
+	AuditRestore   = "restore"
+	AuditPurge     = "purge"
+)
+
+// Resource types recorded in the audit log
+const (
+	ResourceProject         = "project"
+	ResourceTask            = "task"
+	ResourceMembership      = "membership"
+	ResourceAPIKey          = "api_key"
+	ResourceUser            = "user"
+	ResourceWorkspace       = "workspace"
+	ResourceWorkspaceMember = "workspace_member"
+	ResourceTaskDependency  = "task_dependency"
+	ResourceTag             = "tag"
+	ResourceComment         = "comment"
+	ResourceAttachment      = "attachment"
+	ResourceChecklistItem   = "checklist_item"
+	ResourceTimeEntry       = "time_entry"
+)
+
+// Entry of the append-only audit log. Before and After hold the
+// JSON of the resource before and after the change
+type AuditEntry struct {
+	ID           uint      `json:"id" gorm:"primarykey"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+	ActorID      uint      `json:"actor_id" gorm:"index"`
+	Actor        string    `json:"actor"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type" gorm:"index:idx_audit_resource"`
+	ResourceID   uint      `json:"resource_id" gorm:"index:idx_audit_resource"`
+	ProjectID    uint      `json:"project_id" gorm:"index"`
+	Before       JSON      `json:"before"`
+	After        JSON      `json:"after"`
+}
+
+// Raw JSON document stored as text
+type JSON []byte
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *JSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		*j = JSON(v)
+	case []byte:
+		*j = append(JSON{}, v...)
+	case nil:
+		*j = nil
+	default:
+		return fmt.Errorf("can not scan %T into JSON", value)
+	}
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	*j = append(JSON{}, data...)
+	return nil
+}
+
+func (JSON) GormDataType() string {
+	return "text"
+}
+
+// Filter for audit log queries. Zero values do not filter
+type AuditFilter struct {
+	// Only entries of projects the user is a member of or made by the user
+	VisibleTo    uint
+	Actor        string
+	ResourceType string
+	ResourceID   uint
+	From         time.Time
+	To           time.Time
+	Limit        int
+}
@@ -0,0 +1,10 @@
+package model
+
+// Step of the checklist of a task, the items are ordered by their position
+type ChecklistItem struct {
+	ID       uint   `json:"id" gorm:"primarykey"`
+	TaskID   uint   `json:"task_id" gorm:"index"`
+	Text     string `json:"text"`
+	Checked  bool   `json:"checked"`
+	Position int    `json:"position"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment on a task. Replies reference the comment they answer in ParentID
+type Comment struct {
+	gorm.Model
+	TaskID   uint       `json:"task_id" gorm:"index"`
+	ParentID *uint      `json:"parent_id" gorm:"index"`
+	UserID   uint       `json:"-"`
+	User     User       `json:"author"`
+	Body     string     `json:"body"`
+	EditedAt *time.Time `json:"edited_at"`
+
+	// Threaded replies and the body as HTML, only set when listing comments
+	Replies  []Comment `json:"replies,omitempty" gorm:"-"`
+	BodyHTML string    `json:"body_html,omitempty" gorm:"-"`
+}
+
+// Page of top-level comments of a task, oldest first
+type CommentFilter struct {
+	Limit  int
+	Offset int
+}
+
+// Changes the body and records the time of the edit
+func (c *Comment) Edit(body string) {
+	now := time.Now()
+	c.Body = body
+	c.EditedAt = &now
+}
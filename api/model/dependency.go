@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// The task is blocked by the blocker until the blocker is done.
+// Tasks and blockers can be in different projects
+type TaskDependency struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"created_at"`
+	TaskID    uint      `json:"task_id" gorm:"uniqueIndex:idx_task_dependency"`
+	BlockerID uint      `json:"blocker_id" gorm:"uniqueIndex:idx_task_dependency;index"`
+}
+
+// Short form of a task listed as blocker or blocked task
+type TaskReference struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	ProjectID uint   `json:"project_id"`
+	Done      bool   `json:"done"`
+}
+
+func NewTaskReference(task Task) TaskReference {
+	return TaskReference{ID: task.ID, Name: task.Name, ProjectID: task.ProjectID, Done: task.Done}
+}
@@ -0,0 +1,88 @@
+package model
+
+// Unit of the estimates of the tasks of a project
+type EstimateUnit string
+
+const (
+	EstimateMinutes EstimateUnit = "minutes"
+	EstimatePoints  EstimateUnit = "points"
+)
+
+// Largest estimate of a single task in each unit, a year of minutes or a thousand story points
+var maxEstimates = map[EstimateUnit]int{
+	EstimateMinutes: 365 * 24 * 60,
+	EstimatePoints:  1000,
+}
+
+// Parses an estimate unit in lower case, an empty value counts minutes
+func ParseEstimateUnit(value string) (EstimateUnit, bool) {
+	switch EstimateUnit(value) {
+	case "", EstimateMinutes:
+		return EstimateMinutes, true
+	case EstimatePoints:
+		return EstimatePoints, true
+	}
+	return "", false
+}
+
+// Returns the largest estimate of a single task in the unit
+func (u EstimateUnit) Max() int {
+	unit, _ := ParseEstimateUnit(string(u))
+	return maxEstimates[unit]
+}
+
+// Sums of the estimates and remaining effort of some tasks of a project
+type EstimateRollup struct {
+	Tasks     int `json:"tasks"`
+	Estimate  int `json:"estimate"`
+	Remaining int `json:"remaining"`
+}
+
+// Progress of a project, returned by the summary route
+type ProjectSummary struct {
+	ProjectID    uint         `json:"project_id"`
+	Name         string       `json:"name"`
+	EstimateUnit EstimateUnit `json:"estimate_unit"`
+
+	Open EstimateRollup `json:"open"`
+	Done EstimateRollup `json:"done"`
+
+	// Number of tasks without estimate
+	Unestimated int `json:"unestimated"`
+}
+
+// Returns the effort left on the task. Done tasks have none left, open tasks without
+// remaining effort still need their estimate
+func (t Task) RemainingEffort() int {
+	switch {
+	case t.Done:
+		return 0
+	case t.Remaining != nil:
+		return *t.Remaining
+	case t.Estimate != nil:
+		return *t.Estimate
+	}
+	return 0
+}
+
+// Sums up the estimates of the open and done tasks of the project
+func SummarizeProject(project Project, tasks []Task) ProjectSummary {
+	unit, _ := ParseEstimateUnit(string(project.EstimateUnit))
+	summary := ProjectSummary{ProjectID: project.ID, Name: project.Name, EstimateUnit: unit}
+
+	for _, task := range tasks {
+		rollup := &summary.Open
+		if task.Done {
+			rollup = &summary.Done
+		}
+
+		rollup.Tasks++
+		rollup.Remaining += task.RemainingEffort()
+		if task.Estimate != nil {
+			rollup.Estimate += *task.Estimate
+		} else {
+			summary.Unestimated++
+		}
+	}
+	return summary
+}
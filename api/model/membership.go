@@ -0,0 +1,39 @@
+package model
+
+import "gorm.io/gorm"
+
+// Role of a user in a project
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleOwner  Role = "owner"
+)
+
+// Higher ranked roles include the permissions of all lower ranked roles
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// Reports whether the role is one of viewer, editor or owner
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Reports whether the role grants at least the permissions of required
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[required]
+}
+
+// Grants a user access to a project
+type Membership struct {
+	gorm.Model `json:"-"`
+	ProjectID  uint `json:"project_id" gorm:"uniqueIndex:idx_membership"`
+	UserID     uint `json:"-" gorm:"uniqueIndex:idx_membership"`
+	User       User `json:"user"`
+	Role       Role `json:"role"`
+}
@@ -0,0 +1,160 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/rank"
+	"gorm.io/gorm"
+)
+
+// Moves projects created before workspaces existed into the default workspace
+// and replaces the old unique constraint on projects.name with one per workspace
+func migrateWorkspaces(db *gorm.DB) error {
+	workspace := Workspace{}
+	if err := db.FirstOrCreate(&workspace, Workspace{Name: DefaultWorkspaceName}).Error; err != nil {
+		return err
+	}
+
+	err := db.Unscoped().Model(&Project{}).Where("Workspace_ID = 0 OR Workspace_ID IS NULL").
+		UpdateColumn("workspace_id", workspace.ID).Error
+	if err != nil {
+		return err
+	}
+
+	var schema string
+	db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", "projects").Scan(&schema)
+
+	if strings.Contains(schema, "`name` text UNIQUE") {
+		return rebuildTable(db, &Project{})
+	}
+	return nil
+}
+
+// Normalizes the free-form priorities of tasks created before priorities were validated.
+// Unknown priorities become none
+func migratePriorities(db *gorm.DB) error {
+	var values []string
+	if err := db.Unscoped().Model(&Task{}).Where("Priority IS NOT NULL").Distinct("priority").Pluck("priority", &values).Error; err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		priority, _ := ParsePriority(value)
+		if string(priority) == value {
+			continue
+		}
+
+		err := db.Unscoped().Model(&Task{}).Where("Priority = ?", value).UpdateColumn("priority", priority).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return db.Unscoped().Model(&Task{}).Where("Priority IS NULL").UpdateColumn("priority", PriorityNone).Error
+}
+
+// Ranks the projects of each workspace and the tasks of each project created before they
+// had positions. The existing order by ID is kept
+func migratePositions(db *gorm.DB) error {
+	var workspaceIDs []uint
+	if err := db.Unscoped().Model(&Project{}).Where("Position = '' OR Position IS NULL").Distinct("workspace_id").Pluck("workspace_id", &workspaceIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range workspaceIDs {
+		if err := spreadPositions(db, &Project{}, "Workspace_ID = ?", id); err != nil {
+			return err
+		}
+	}
+
+	var projectIDs []uint
+	if err := db.Unscoped().Model(&Task{}).Where("Position = '' OR Position IS NULL").Distinct("project_id").Pluck("project_id", &projectIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range projectIDs {
+		if err := spreadPositions(db, &Task{}, "Project_ID = ?", id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Gives the rows of the model matching the condition evenly spread ranks in their current order
+func spreadPositions(db *gorm.DB, value interface{}, condition string, args ...interface{}) error {
+	var ids []uint
+	err := db.Unscoped().Model(value).Where(condition, args...).Order("Position = '' OR Position IS NULL").Order("Position").Order("ID").Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	positions := rank.Spread(len(ids))
+	return db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Unscoped().Model(value).Where("ID = ?", id).UpdateColumn("position", positions[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SQLite can not drop the constraints of a column. Recreates the table
+// of the model with its current schema and copies all rows over
+func rebuildTable(db *gorm.DB, value interface{}) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(value); err != nil {
+		return err
+	}
+	table := stmt.Schema.Table
+	old := table + "_old"
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Indexes keep their names when a table is renamed
+		var indexes []string
+		err := tx.Raw("SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = ? AND sql IS NOT NULL", table).
+			Scan(&indexes).Error
+		if err != nil {
+			return err
+		}
+		for _, index := range indexes {
+			if err := tx.Migrator().DropIndex(table, index); err != nil {
+				return err
+			}
+		}
+
+		// Foreign keys of other tables keep pointing to the original name
+		if err := tx.Exec("PRAGMA legacy_alter_table = ON").Error; err != nil {
+			return err
+		}
+		defer tx.Exec("PRAGMA legacy_alter_table = OFF")
+
+		if err := tx.Migrator().RenameTable(table, old); err != nil {
+			return err
+		}
+		if err := tx.Migrator().CreateTable(value); err != nil {
+			return err
+		}
+
+		var columns []string
+		err = tx.Raw("SELECT name FROM pragma_table_info(?) WHERE name IN (SELECT name FROM pragma_table_info(?))", old, table).
+			Scan(&columns).Error
+		if err != nil {
+			return err
+		}
+		list := "`" + strings.Join(columns, "`, `") + "`"
+
+		if err := tx.Exec(fmt.Sprintf("INSERT INTO `%v` (%v) SELECT %v FROM `%v`", table, list, list, old)).Error; err != nil {
+			return err
+		}
+		return tx.Migrator().DropTable(old)
+	})
+}
+
+// Drops the unique index on project names that also covered deleted projects, the
+// index that replaces it only covers projects outside the trash
+func migrateProjectNameIndex(db *gorm.DB) error {
+	if !db.Migrator().HasIndex(&Project{}, "idx_workspace_project_name") {
+		return nil
+	}
+	return db.Migrator().DropIndex(&Project{}, "idx_workspace_project_name")
+}
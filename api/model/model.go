@@ -1,20 +1,46 @@
 package model
 
 import (
+	"log"
 	"time"
 
 	"gorm.io/gorm"
 )
 
 type Project struct {
-	gorm.Model `json:"id" gorm:"unique"`
-	Name       string `json:"name" gorm:"unique"`
-	Archived   bool   `json:"archived"`
-	Tasks      []Task `gorm:"ForeignKey:ProjectID" json:"tasks"`
+	gorm.Model  `json:"id" gorm:"unique"`
+	Name        string `json:"name" gorm:"uniqueIndex:idx_workspace_project_live_name,where:deleted_at IS NULL"`
+	Description string `json:"description"`
+	Archived    bool   `json:"archived"`
+	UserID      uint   `json:"user_id"`
+	WorkspaceID uint   `json:"workspace_id" gorm:"uniqueIndex:idx_workspace_project_live_name,where:deleted_at IS NULL"`
+	Tasks       []Task `gorm:"ForeignKey:ProjectID" json:"tasks"`
+
+	// Unit of the estimates of its tasks, minutes if empty
+	EstimateUnit EstimateUnit `json:"estimate_unit"`
+
+	// Rank of the project in the project lists of its workspace
+	Position string `json:"position" gorm:"index"`
+
+	// Description as HTML, only set for ?render=html
+	DescriptionHTML string `json:"description_html,omitempty" gorm:"-"`
 }
 
 func DbMigrate(db *gorm.DB) *gorm.DB {
-	db.AutoMigrate(&Project{}, &Task{})
+	db.AutoMigrate(&Project{}, &Task{}, &User{}, &Token{}, &Membership{}, &APIKey{}, &AuditEntry{}, &Workspace{}, &WorkspaceMember{}, &TaskDependency{}, &Tag{}, &Comment{}, &Attachment{}, &ChecklistItem{}, &TimeEntry{}, &Revision{})
+
+	if err := migrateWorkspaces(db); err != nil {
+		log.Fatalf("could not migrate projects into workspaces: %v", err)
+	}
+	if err := migratePriorities(db); err != nil {
+		log.Fatalf("could not normalize task priorities: %v", err)
+	}
+	if err := migratePositions(db); err != nil {
+		log.Fatalf("could not rank projects and tasks: %v", err)
+	}
+	if err := migrateProjectNameIndex(db); err != nil {
+		log.Fatalf("could not replace the unique index on project names: %v", err)
+	}
 	return db
 }
 
@@ -28,11 +54,58 @@ func (p *Project) UnArchiveProject() {
 
 type Task struct {
 	gorm.Model
-	Name      string     `json:"name"`
-	Priority  string     `json:"priority"`
-	Deadline  *time.Time `gorm:"default:null" json:"deadline"`
-	Done      bool       `json:"done"`
-	ProjectID uint       `json:"project_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Priority    Priority   `json:"priority"`
+	Deadline    *time.Time `gorm:"default:null" json:"deadline"`
+	Done        bool       `json:"done"`
+	ProjectID   uint       `json:"project_id"`
+	UserID      uint       `json:"user_id"`
+	ParentID    *uint      `json:"parent_id" gorm:"index"`
+
+	// Task lists hide the task until its start, the scheduled date is the day work on it is planned
+	StartAt      *time.Time `gorm:"default:null" json:"start_at"`
+	ScheduledFor *time.Time `gorm:"default:null" json:"scheduled_for"`
+
+	// Rank of the task in the task lists of its project
+	Position string `json:"position" gorm:"index"`
+
+	// RRULE of a repeating task and whether the next deadline follows the due or the completion date
+	Recurrence string     `json:"recurrence"`
+	RepeatFrom RepeatFrom `json:"repeat_from"`
+
+	// Planned and remaining effort in the estimate unit of the project
+	Estimate  *int `json:"estimate"`
+	Remaining *int `json:"remaining"`
+
+	Tags      []Tag  `json:"tags" gorm:"many2many:task_tags"`
+	Assignees []User `json:"assignees" gorm:"many2many:task_assignees"`
+
+	// Description as HTML, only set for ?render=html
+	DescriptionHTML string `json:"description_html,omitempty" gorm:"-"`
+
+	// Counted from the subtasks, not stored
+	Subtasks SubtaskProgress `json:"subtasks" gorm:"-"`
+
+	// Counted from the checklist, not stored
+	ChecklistDone  int `json:"checklist_done" gorm:"-"`
+	ChecklistTotal int `json:"checklist_total" gorm:"-"`
+
+	// Checklist items of the task, only set for a single task
+	Checklist []ChecklistItem `json:"checklist,omitempty" gorm:"-"`
+
+	// Dependencies of the task, only set for a single task
+	BlockedBy []TaskReference `json:"blocked_by,omitempty" gorm:"-"`
+	Blocks    []TaskReference `json:"blocks,omitempty" gorm:"-"`
+}
+
+// Maximum number of levels of a task hierarchy, a top-level task is on level 1
+const MaxTaskDepth = 3
+
+// Number of direct subtasks of a task and how many of them are done
+type SubtaskProgress struct {
+	Total int `json:"total"`
+	Done  int `json:"done"`
 }
 
 func (t *Task) CompleteTask() {
@@ -42,3 +115,42 @@ func (t *Task) CompleteTask() {
 func (t *Task) ReopenTask() {
 	t.Done = false
 }
+
+// Reports whether the task has started at the given time. Tasks without a start date always have
+func (t Task) Started(now time.Time) bool {
+	return t.StartAt == nil || !t.StartAt.After(now)
+}
+
+// Pushes the start of the task back by the duration, counted from its start if that is still ahead
+func (t *Task) SnoozeFor(d time.Duration, now time.Time) {
+	from := now
+	if t.StartAt != nil && t.StartAt.After(now) {
+		from = *t.StartAt
+	}
+	t.SnoozeUntil(from.Add(d))
+}
+
+// Hides the task until the given time
+func (t *Task) SnoozeUntil(until time.Time) {
+	t.StartAt = &until
+}
+
+// Orders of task lists
+const (
+	SortPriority = "priority"
+	SortDeadline = "deadline"
+)
+
+// Options for listing the tasks of a project
+type TaskFilter struct {
+	// Sorts by priority then deadline, by deadline or by position if empty
+	Sort string
+
+	// Only tasks with any or with all of the tag names if not empty
+	Tags  []string
+	Match string
+
+	// Only tasks assigned to any of the user names or, if Unassigned is set, to nobody
+	Assignees  []string
+	Unassigned bool
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Priority of a task, from none to urgent
+type Priority string
+
+const (
+	PriorityNone   Priority = "none"
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Priorities in ascending order, the index is the level of a priority
+var priorityLevels = []Priority{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// Other names used for priorities before they were validated
+var priorityAliases = map[string]Priority{
+	"":         PriorityNone,
+	"normal":   PriorityMedium,
+	"critical": PriorityUrgent,
+}
+
+// Returns the level of the priority from 0 for none to 4 for urgent or -1 for unknown priorities
+func (p Priority) Level() int {
+	for level, priority := range priorityLevels {
+		if p == priority {
+			return level
+		}
+	}
+	return -1
+}
+
+// Parses a priority name in any case, an alias or a level from 0 to 4. An empty value is no priority
+func ParsePriority(value string) (Priority, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if priority := Priority(value); priority.Level() >= 0 {
+		return priority, true
+	}
+
+	if priority, ok := priorityAliases[value]; ok {
+		return priority, true
+	}
+
+	if level, err := strconv.Atoi(value); err == nil && level >= 0 && level < len(priorityLevels) {
+		return priorityLevels[level], true
+	}
+
+	return PriorityNone, false
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/rrule"
+)
+
+// Date the next occurrence of a repeating task is computed from
+type RepeatFrom string
+
+const (
+	RepeatFromDue        RepeatFrom = "due"
+	RepeatFromCompletion RepeatFrom = "completion"
+)
+
+// Parses the repeat mode of a task in lower case, an empty value repeats from the due date
+func ParseRepeatFrom(value string) (RepeatFrom, bool) {
+	switch RepeatFrom(value) {
+	case "", RepeatFromDue:
+		return RepeatFromDue, true
+	case RepeatFromCompletion:
+		return RepeatFromCompletion, true
+	}
+	return "", false
+}
+
+// Returns the date the next occurrences of the task follow. That is the deadline or for tasks repeating
+// from their completion the given completion date at the time of day of the deadline
+func (t Task) RecurrenceStart(completed time.Time) time.Time {
+	if t.Deadline == nil {
+		return completed
+	}
+	if t.RepeatFrom != RepeatFromCompletion {
+		return *t.Deadline
+	}
+
+	deadline := *t.Deadline
+	completed = completed.In(deadline.Location())
+	return time.Date(completed.Year(), completed.Month(), completed.Day(), deadline.Hour(), deadline.Minute(), deadline.Second(), 0, deadline.Location())
+}
+
+// Returns the next occurrence of a repeating task completed at the given time as a new open task. Its deadline is
+// shifted, the occurrence is counted in the rule and the remaining effort starts over. Returns false if the task
+// does not repeat or its rule has no further occurrences
+func (t Task) NextOccurrence(completed time.Time) (Task, bool) {
+	if t.Recurrence == "" {
+		return Task{}, false
+	}
+
+	rule, err := rrule.Parse(t.Recurrence)
+	if err != nil || rule.Count == 1 {
+		return Task{}, false
+	}
+
+	next, ok := rule.Next(t.RecurrenceStart(completed))
+	if !ok {
+		return Task{}, false
+	}
+
+	if rule.Count > 1 {
+		rule.Count--
+	}
+
+	occurrence := Task{Name: t.Name, Description: t.Description, Priority: t.Priority, Deadline: &next, ProjectID: t.ProjectID, UserID: t.UserID, ParentID: t.ParentID,
+		StartAt: t.StartAt, ScheduledFor: t.ScheduledFor, Recurrence: rule.String(), RepeatFrom: t.RepeatFrom, Estimate: t.Estimate}
+
+	// Start and scheduled date keep their distance to the deadline
+	if t.Deadline != nil {
+		shift := next.Sub(*t.Deadline)
+		occurrence.StartAt = shiftTime(t.StartAt, shift)
+		occurrence.ScheduledFor = shiftTime(t.ScheduledFor, shift)
+	}
+	return occurrence, true
+}
+
+func shiftTime(t *time.Time, d time.Duration) *time.Time {
+	if t == nil {
+		return nil
+	}
+	shifted := t.Add(d)
+	return &shifted
+}
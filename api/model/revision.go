@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Prior state of a task or project, recorded by every update that changes it
+type Revision struct {
+	ID           uint      `json:"-" gorm:"primarykey"`
+	CreatedAt    time.Time `json:"created_at"`
+	ResourceType string    `json:"resource_type" gorm:"index:idx_revision_resource"`
+	ResourceID   uint      `json:"resource_id" gorm:"index:idx_revision_resource"`
+
+	// Counts the revisions of each resource from 1
+	Number int `json:"revision"`
+
+	// Columns the update changed and the resource before it
+	Changed  JSON `json:"changed"`
+	Snapshot JSON `json:"snapshot"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "gorm.io/gorm"
+
+// Label of tasks like "bug" or "errand". Tags belong to a workspace and
+// can be attached to tasks of every project in it
+type Tag struct {
+	gorm.Model
+	Name        string `json:"name" gorm:"uniqueIndex:idx_workspace_tag_name"`
+	WorkspaceID uint   `json:"workspace_id" gorm:"uniqueIndex:idx_workspace_tag_name"`
+	UserID      uint   `json:"user_id"`
+}
+
+// Matching of the tags of a task filter
+const (
+	MatchAny = "any"
+	MatchAll = "all"
+)
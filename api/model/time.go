@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// Time a user spent on a task. Entries of running timers have no end, every user runs at most one timer
+type TimeEntry struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	TaskID    uint       `json:"task_id" gorm:"index"`
+	ProjectID uint       `json:"project_id" gorm:"index"`
+	UserID    uint       `json:"user_id" gorm:"index;uniqueIndex:idx_time_entry_running_user,where:ended_at IS NULL"`
+	Start     time.Time  `json:"start" gorm:"column:started_at"`
+	End       *time.Time `json:"end" gorm:"column:ended_at"`
+	Note      string     `json:"note"`
+
+	// Computed from start and end, not stored
+	Seconds int64 `json:"seconds" gorm:"-"`
+}
+
+// Filter for time entry queries. Zero values do not filter
+type TimeEntryFilter struct {
+	TaskID    uint
+	ProjectID uint
+	UserID    uint
+	Running   bool
+
+	// Only entries started in the range
+	From time.Time
+	To   time.Time
+}
+
+// Time spent on a task or a project within a range
+type TimeReport struct {
+	From    *time.Time  `json:"from,omitempty"`
+	To      *time.Time  `json:"to,omitempty"`
+	Seconds int64       `json:"seconds"`
+	Tasks   []TaskTime  `json:"tasks,omitempty"`
+	Entries []TimeEntry `json:"entries,omitempty"`
+}
+
+// Time spent on one task of a project report
+type TaskTime struct {
+	TaskID  uint   `json:"task_id"`
+	Name    string `json:"name"`
+	Seconds int64  `json:"seconds"`
+}
+
+// Returns whether the timer of the entry is still running
+func (e TimeEntry) Running() bool {
+	return e.End == nil
+}
+
+// Sets the seconds of the entry, running timers are counted until now
+func (e *TimeEntry) Measure(now time.Time) {
+	end := now
+	if e.End != nil {
+		end = *e.End
+	}
+	e.Seconds = int64(end.Sub(e.Start).Seconds())
+}
+
+// Stops the timer of the entry
+func (e *TimeEntry) Stop(now time.Time) {
+	e.End = &now
+	e.Measure(now)
+}
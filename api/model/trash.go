@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Deleted project or task that can still be restored or purged. Tasks of a deleted project
+// and subtasks of a deleted task are restored and purged with it and not listed
+type TrashItem struct {
+	ResourceType string    `json:"resource_type"`
+	ID           uint      `json:"id"`
+	Name         string    `json:"name"`
+	ProjectID    uint      `json:"project_id"`
+	DeletedAt    time.Time `json:"deleted_at"`
+
+	// Name of the project of a task
+	Project string `json:"project,omitempty"`
+}
+
+// Filter for the trash. Zero values do not filter
+type TrashFilter struct {
+	// Only items of projects the user owns
+	OwnerID uint
+
+	// Only items deleted before
+	Before time.Time
+}
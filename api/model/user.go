@@ -0,0 +1,80 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Lifetime of a token issued by POST /login
+const TokenLifetime = 24 * time.Hour
+
+type User struct {
+	gorm.Model
+	Name         string `json:"name" gorm:"unique"`
+	PasswordHash string `json:"-"`
+	// sub claim of the identity provider for users that log in with a JWT
+	Subject *string `json:"-" gorm:"uniqueIndex"`
+}
+
+// Hashes the password with bcrypt and stores the hash
+func (u *User) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+// Compares the password with the stored hash
+func (u *User) CheckPassword(password string) bool {
+	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
+	return err == nil
+}
+
+// Bearer token issued on login. Only the sha256 hash
+// of the token is stored in the database
+type Token struct {
+	gorm.Model
+	UserID    uint      `json:"user_id"`
+	Hash      string    `json:"-" gorm:"unique"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Creates a new token for a user. Returns the token that has to
+// be stored and the plaintext token that is handed to the client
+func NewToken(userID uint) (Token, string, error) {
+	secret, err := randomSecret()
+	if err != nil {
+		return Token{}, "", err
+	}
+
+	token := Token{
+		UserID:    userID,
+		Hash:      HashToken(secret),
+		ExpiresAt: time.Now().Add(TokenLifetime),
+	}
+
+	return token, secret, nil
+}
+
+// Returns the hex encoded sha256 hash of a plaintext token
+func HashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Returns 32 random bytes hex encoded
+func randomSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
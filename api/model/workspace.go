@@ -0,0 +1,28 @@
+package model
+
+import "gorm.io/gorm"
+
+// Name of the workspace used by the routes without /workspaces/{ws} prefix.
+// Every user can create projects in it
+const DefaultWorkspaceName = "default"
+
+// Workspaces scope projects, project names are unique per workspace
+type Workspace struct {
+	gorm.Model
+	Name   string `json:"name" gorm:"uniqueIndex"`
+	UserID uint   `json:"user_id"`
+}
+
+func (w *Workspace) IsDefault() bool {
+	return w.Name == DefaultWorkspaceName
+}
+
+// Grants a user access to a workspace. Editors can create
+// projects in the workspace and owners manage its members
+type WorkspaceMember struct {
+	gorm.Model  `json:"-"`
+	WorkspaceID uint `json:"workspace_id" gorm:"uniqueIndex:idx_workspace_member"`
+	UserID      uint `json:"-" gorm:"uniqueIndex:idx_workspace_member"`
+	User        User `json:"user"`
+	Role        Role `json:"role"`
+}
@@ -0,0 +1,89 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for moving tasks between projects
+func TestMoveTasks(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	project := func(name string) model.Project {
+		return db.GetProject(db.GetWorkspace(model.DefaultWorkspaceName).ID, name)
+	}
+
+	move := func(url, target, onConflict string) *httptest.ResponseRecorder {
+		return serveWithToken(server, alice, http.MethodPost, url+"/move", fmt.Sprintf(`{"project_id": %d, "on_conflict": %q}`, project(target).ID, onConflict))
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "inbox"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "garden"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "old"}`)
+	serveWithToken(server, alice, http.MethodPut, "/projects/old/archive", "")
+
+	serveWithToken(server, alice, http.MethodPost, "/projects/inbox/tasks", `{"name": "hedge"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/inbox/tasks/hedge/subtasks", `{"name": "buy shears"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/inbox/tasks/hedge/comments", `{"body": "Before June"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/inbox/tasks/hedge/time", `{"start": "2030-05-01T09:00:00Z", "end": "2030-05-01T10:00:00Z"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/inbox/tasks", `{"name": "lawn"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/garden/tasks", `{"name": "lawn"}`)
+
+	t.Run("Move a task with its subtasks and data", func(t *testing.T) {
+		response := move("/projects/inbox/tasks/hedge", "garden", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/garden/tasks/buy shears", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/inbox/tasks/hedge", "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+
+		var comments []model.Comment
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, "/projects/garden/tasks/hedge/comments", "").Body).Decode(&comments)
+		if len(comments) != 1 || comments[0].Body != "Before June" {
+			t.Errorf("got comments %v, want the comment of hedge", comments)
+		}
+
+		var report model.TimeReport
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, "/projects/garden/time", "").Body).Decode(&report)
+		if report.Seconds != 3600 {
+			t.Errorf("got report %v, want an hour on hedge", report)
+		}
+	})
+
+	t.Run("Subtasks are not moved on their own", func(t *testing.T) {
+		response := move("/projects/garden/tasks/buy shears", "inbox", "")
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Name collisions fail unless renamed", func(t *testing.T) {
+		response := move("/projects/inbox/tasks/lawn", "garden", "")
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+
+		response = move("/projects/inbox/tasks/lawn", "garden", "merge")
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = move("/projects/inbox/tasks/lawn", "garden", "rename")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/garden/tasks/lawn (2)", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("Archived projects do not accept tasks", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, "/projects/inbox/tasks", `{"name": "compost"}`)
+
+		response := move("/projects/inbox/tasks/compost", "old", "")
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+
+		response = move("/projects/inbox/tasks/compost", "inbox", "")
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,207 @@
+// Package oidc validates JSON Web Tokens issued by an OpenID Connect
+// identity provider against the keys of a JSON Web Key Set.
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Allowed clock difference between the identity provider and this server
+const clockSkew = time.Minute
+
+var (
+	ErrMalformed        = errors.New("token is malformed")
+	ErrUnsupportedAlg   = errors.New("token algorithm is not RS256 or ES256")
+	ErrUnknownKey       = errors.New("token is signed with an unknown key")
+	ErrInvalidSignature = errors.New("token signature is invalid")
+	ErrExpired          = errors.New("token is expired")
+	ErrNotYetValid      = errors.New("token is not valid yet")
+	ErrIssuer           = errors.New("token has the wrong issuer")
+	ErrAudience         = errors.New("token has the wrong audience")
+	ErrSubject          = errors.New("token has no subject")
+)
+
+// Validates tokens signed by the keys of a key set for an issuer and audience
+type Validator struct {
+	Issuer   string
+	Audience string
+	Keys     *KeySet
+
+	// Returns the current time, replaced in tests
+	Now func() time.Time
+}
+
+// Claims of a validated token
+type Claims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	ExpiresAt         *int64   `json:"exp"`
+	NotBefore         *int64   `json:"nbf"`
+	PreferredUsername string   `json:"preferred_username"`
+}
+
+// The aud claim is either a single string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(aud string) bool {
+	for _, s := range a {
+		if s == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// Creates a validator with keys loaded from a JWKS file or URL
+func NewValidator(issuer, audience, jwksSource string) (*Validator, error) {
+	keys, err := LoadKeySet(jwksSource)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Validator{Issuer: issuer, Audience: audience, Keys: keys}, nil
+}
+
+// Reports whether the bearer token has the form of a JWT
+func LooksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Verifies the signature of the token and checks the iss, aud, exp and nbf claims
+func (v *Validator) Validate(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, ErrMalformed
+	}
+
+	key, ok := v.Keys.key(header.Kid)
+	if !ok {
+		return Claims{}, ErrUnknownKey
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, ErrMalformed
+	}
+
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return Claims{}, err
+	}
+
+	claims := Claims{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, ErrMalformed
+	}
+
+	if err := v.checkClaims(claims); err != nil {
+		return Claims{}, err
+	}
+
+	return claims, nil
+}
+
+func (v *Validator) checkClaims(claims Claims) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+
+	if claims.ExpiresAt == nil || now.After(time.Unix(*claims.ExpiresAt, 0).Add(clockSkew)) {
+		return ErrExpired
+	}
+
+	if claims.NotBefore != nil && now.Add(clockSkew).Before(time.Unix(*claims.NotBefore, 0)) {
+		return ErrNotYetValid
+	}
+
+	if claims.Issuer != v.Issuer {
+		return ErrIssuer
+	}
+
+	if !claims.Audience.contains(v.Audience) {
+		return ErrAudience
+	}
+
+	if claims.Subject == "" {
+		return ErrSubject
+	}
+
+	return nil
+}
+
+// Verifies a RS256 or ES256 signature. The algorithm has to match the key type
+// so that a token can not pick a weaker verification than the key was issued for
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	hash := sha256.Sum256([]byte(signed))
+
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrUnknownKey
+		}
+		if rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, hash[:], signature) != nil {
+			return ErrInvalidSignature
+		}
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return ErrUnknownKey
+		}
+		// ES256 signatures are the concatenation of r and s with 32 bytes each
+		if len(signature) != 64 {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, hash[:], r, s) {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrUnsupportedAlg
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("decoding segment: %v", err)
+	}
+	return json.Unmarshal(data, v)
+}
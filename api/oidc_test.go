@@ -0,0 +1,185 @@
+package api_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/oidc"
+)
+
+const (
+	testIssuer   = "https://id.example.com"
+	testAudience = "todo-api"
+)
+
+// Signing keys of the test identity provider
+type testIdentityProvider struct {
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+}
+
+// Creates signing keys and writes their public keys as JWKS file
+func setUpIdentityProvider(t *testing.T) (idp testIdentityProvider, jwksFile string) {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assertError(t, "generate RSA key", err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assertError(t, "generate EC key", err)
+
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(ecKey.X.Bytes()), "y": encode(ecKey.Y.Bytes())},
+		},
+	})
+
+	jwksFile = filepath.Join(t.TempDir(), "jwks.json")
+	err = ioutil.WriteFile(jwksFile, jwks, 0644)
+	assertError(t, "write JWKS file", err)
+
+	return testIdentityProvider{rsaKey: rsaKey, ecKey: ecKey}, jwksFile
+}
+
+// Signs the claims with the key for alg
+func (idp testIdentityProvider) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, idp.rsaKey, crypto.SHA256, hash[:])
+	case "ES256":
+		r, s, _ := ecdsa.Sign(rand.Reader, idp.ecKey, hash[:])
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// Returns valid claims for the subject
+func validClaims(subject string) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":                testIssuer,
+		"aud":                []string{testAudience, "other"},
+		"sub":                subject,
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"preferred_username": "erin",
+	}
+}
+
+// Tests for JWT authentication with a JWKS file
+func TestOIDCAuthentication(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	idp, jwksFile := setUpIdentityProvider(t)
+
+	validator, err := oidc.NewValidator(testIssuer, testAudience, jwksFile)
+	assertError(t, "create validator", err)
+	server.OIDC = validator
+
+	getMe := func(token string) (*httptest.ResponseRecorder, model.User) {
+		request := newRequestWithToken(http.MethodGet, "/me", token, nil)
+		response := serveRequest(server, request)
+
+		user := model.User{}
+		json.NewDecoder(response.Body).Decode(&user)
+		return response, user
+	}
+
+	t.Run("RS256 token creates a local user", func(t *testing.T) {
+		response, user := getMe(idp.sign(t, "RS256", "rsa-1", validClaims("sub-1")))
+
+		assertResponseStatus(t, response.Code, http.StatusOK)
+		assertResponseBody(t, user.Name, "erin")
+	})
+
+	t.Run("ES256 token maps to the same local user", func(t *testing.T) {
+		claims := validClaims("sub-1")
+		claims["aud"] = testAudience
+		response, user := getMe(idp.sign(t, "ES256", "ec-1", claims))
+
+		assertResponseStatus(t, response.Code, http.StatusOK)
+		assertResponseBody(t, user.Name, "erin")
+
+		if user.ID != db.GetUser("erin").ID {
+			t.Errorf("subject was mapped to a different user")
+		}
+	})
+
+	t.Run("Taken usernames fall back to the subject", func(t *testing.T) {
+		response, user := getMe(idp.sign(t, "RS256", "rsa-1", validClaims("sub-2")))
+
+		assertResponseStatus(t, response.Code, http.StatusOK)
+		assertResponseBody(t, user.Name, "sub-2")
+	})
+
+	invalid := []struct {
+		name   string
+		alg    string
+		kid    string
+		change func(claims map[string]interface{})
+	}{
+		{"expired token", "RS256", "rsa-1", func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"token without exp", "RS256", "rsa-1", func(c map[string]interface{}) { delete(c, "exp") }},
+		{"wrong issuer", "RS256", "rsa-1", func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }},
+		{"wrong audience", "ES256", "ec-1", func(c map[string]interface{}) { c["aud"] = "other" }},
+		{"unknown key", "RS256", "rsa-2", func(c map[string]interface{}) {}},
+		{"algorithm does not match key", "ES256", "rsa-1", func(c map[string]interface{}) {}},
+		{"unsupported algorithm", "none", "rsa-1", func(c map[string]interface{}) {}},
+	}
+
+	for _, c := range invalid {
+		t.Run(c.name, func(t *testing.T) {
+			claims := validClaims("sub-1")
+			c.change(claims)
+
+			response, _ := getMe(idp.sign(t, c.alg, c.kid, claims))
+
+			assertResponseStatus(t, response.Code, http.StatusUnauthorized)
+		})
+	}
+
+	t.Run("tampered payload", func(t *testing.T) {
+		token := idp.sign(t, "RS256", "rsa-1", validClaims("sub-1"))
+		other := idp.sign(t, "RS256", "rsa-1", validClaims("sub-3"))
+
+		// header and payload of the second token with the signature of the first
+		tampered := other[:strings.LastIndex(other, ".")] + token[strings.LastIndex(token, "."):]
+
+		response, _ := getMe(tampered)
+
+		assertResponseStatus(t, response.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("JWTs are rejected when no validator is configured", func(t *testing.T) {
+		server.OIDC = nil
+		response, _ := getMe(idp.sign(t, "RS256", "rsa-1", validClaims("sub-1")))
+
+		assertResponseStatus(t, response.Code, http.StatusUnauthorized)
+	})
+}
@@ -0,0 +1,115 @@
+package api_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for full updates with PUT and merge patches with PATCH on tasks
+func TestTaskUpdates(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "homework"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/homework/tasks", `{"name": "math", "priority": "high", "deadline": "2021-06-01T12:00:00Z"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/homework/tasks", `{"name": "biology"}`)
+
+	getTask := func(name string) model.Task {
+		workspace := db.GetWorkspace(model.DefaultWorkspaceName)
+		return db.GetTask(db.GetProject(workspace.ID, "homework"), name)
+	}
+
+	t.Run("PATCH changes only the fields in the patch", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPatch, "/projects/homework/tasks/math", `{"priority": "low"}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		task := getTask("math")
+		assertResponseBody(t, string(task.Priority), "low")
+		if task.Deadline == nil {
+			t.Error("deadline should not have been cleared")
+		}
+	})
+
+	t.Run("PATCH with null clears the deadline", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPatch, "/projects/homework/tasks/math", `{"deadline": null}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if task := getTask("math"); task.Deadline != nil {
+			t.Errorf("deadline was not cleared: %v", task.Deadline)
+		}
+	})
+
+	t.Run("PUT replaces all fields", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/homework/tasks/math", `{"name": "algebra", "deadline": "2021-07-01T12:00:00Z"}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		task := getTask("algebra")
+		assertResponseBody(t, string(task.Priority), "none")
+		if task.Deadline == nil || task.Deadline.Month() != 7 {
+			t.Errorf("deadline was not replaced: %v", task.Deadline)
+		}
+	})
+
+	t.Run("Task names stay unique", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPatch, "/projects/homework/tasks/algebra", `{"name": "biology"}`)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+// Tests for route PATCH /projects/{projectname}/tasks/{taskname}
+func TestPatchTask(t *testing.T) {
+	server, _ := setupTaskTests()
+
+	t.Run("PATCH rejects other content types", func(t *testing.T) {
+		request := newAuthenticatedRequest(http.MethodPatch, "/projects/homework/tasks/math", bytes.NewBufferString(`{"priority": "high"}`))
+		request.Header.Set("content-type", "text/plain")
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusUnsupportedMediaType)
+	})
+
+	t.Run("PATCH rejects fields that can not be changed", func(t *testing.T) {
+		request := newAuthenticatedRequest(http.MethodPatch, "/projects/homework/tasks/math", bytes.NewBufferString(`{"project_id": 5}`))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("PATCH can not remove the name", func(t *testing.T) {
+		request := newAuthenticatedRequest(http.MethodPatch, "/projects/homework/tasks/math", bytes.NewBufferString(`{"name": null}`))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("PUT requires a name", func(t *testing.T) {
+		request := newAuthenticatedRequest(http.MethodPut, "/projects/homework/tasks/math", bytes.NewBufferString(`{"priority": "high"}`))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	// The stub loses the project of updated tasks, so this runs last
+	t.Run("PATCH accepts merge patches", func(t *testing.T) {
+		request := newAuthenticatedRequest(http.MethodPatch, "/projects/homework/tasks/math", bytes.NewBufferString(`{"priority": "low"}`))
+		request.Header.Set("content-type", "application/merge-patch+json")
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusOK)
+	})
+}
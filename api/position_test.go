@@ -0,0 +1,148 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/rank"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+	"gorm.io/gorm"
+)
+
+// Tests that ranks can always be placed between two others
+func TestRanks(t *testing.T) {
+	t.Run("Ranks between two ranks", func(t *testing.T) {
+		cases := [][2]string{{"", ""}, {"", "1"}, {"V", ""}, {"1", "2"}, {"A", "A1"}, {"09", "1"}, {"z", "zV"}}
+
+		for _, c := range cases {
+			got, err := rank.Between(c[0], c[1])
+			assertError(t, "rank between "+c[0]+" and "+c[1], err)
+
+			if got <= c[0] || (c[1] != "" && got >= c[1]) {
+				t.Errorf("got rank %q, want a rank between %q and %q", got, c[0], c[1])
+			}
+		}
+	})
+
+	t.Run("Ranks out of order are rejected", func(t *testing.T) {
+		for _, c := range [][2]string{{"B", "A"}, {"A", "A"}, {"A0", "B"}, {"A-", ""}} {
+			if _, err := rank.Between(c[0], c[1]); err == nil {
+				t.Errorf("got no error for %q and %q", c[0], c[1])
+			}
+		}
+	})
+
+	t.Run("Appended ranks grow slowly", func(t *testing.T) {
+		last := ""
+		for i := 0; i < 1000; i++ {
+			next := rank.After(last)
+			if next <= last {
+				t.Fatalf("got rank %q after %q", next, last)
+			}
+			last = next
+		}
+		if len(last) > 20 {
+			t.Errorf("got rank %q after 1000 appends, want a short rank", last)
+		}
+	})
+
+	t.Run("Spread ranks are ascending", func(t *testing.T) {
+		ranks := rank.Spread(500)
+		for i := 1; i < len(ranks); i++ {
+			if ranks[i] <= ranks[i-1] {
+				t.Fatalf("got rank %q after %q", ranks[i], ranks[i-1])
+			}
+		}
+	})
+}
+
+// Tests for moving tasks and projects
+func TestPositions(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	taskNames := func() string {
+		var tasks []model.Task
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, "/projects/board/tasks", "").Body).Decode(&tasks)
+
+		names := []string{}
+		for _, task := range tasks {
+			names = append(names, task.Name)
+		}
+		return strings.Join(names, " ")
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "board"}`)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		serveWithToken(server, alice, http.MethodPost, "/projects/board/tasks", fmt.Sprintf(`{"name": %q}`, name))
+	}
+	serveWithToken(server, alice, http.MethodPost, "/projects/board/tasks/a/subtasks", `{"name": "a1"}`)
+
+	id := func(name string) uint {
+		return db.GetTask(db.GetProject(db.GetWorkspace(model.DefaultWorkspaceName).ID, "board"), name).ID
+	}
+
+	t.Run("New tasks are added at the end", func(t *testing.T) {
+		assertResponseBody(t, taskNames(), "a b c d a1")
+	})
+
+	t.Run("Move tasks before and after siblings", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/board/tasks/d/position", fmt.Sprintf(`{"before": %d}`, id("a")))
+		assertResponseStatus(t, response.Code, http.StatusOK)
+		assertResponseBody(t, taskNames(), "d a b c a1")
+
+		response = serveWithToken(server, alice, http.MethodPut, "/projects/board/tasks/a/position", fmt.Sprintf(`{"after": %d}`, id("c")))
+		assertResponseStatus(t, response.Code, http.StatusOK)
+		assertResponseBody(t, taskNames(), "d b c a a1")
+
+		response = serveWithToken(server, alice, http.MethodPut, "/projects/board/tasks/b/position", fmt.Sprintf(`{"before": %d}`, id("c")))
+		assertResponseStatus(t, response.Code, http.StatusOK)
+		assertResponseBody(t, taskNames(), "d b c a a1")
+	})
+
+	t.Run("Tasks stay with their siblings", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/board/tasks/a1/position", fmt.Sprintf(`{"before": %d}`, id("d")))
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = serveWithToken(server, alice, http.MethodPut, "/projects/board/tasks/b/position", fmt.Sprintf(`{"before": %d, "after": %d}`, id("c"), id("d")))
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Move projects", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "inbox"}`)
+		board := db.GetProject(db.GetWorkspace(model.DefaultWorkspaceName).ID, "board")
+
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/inbox/position", fmt.Sprintf(`{"before": %d}`, board.ID))
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		var projects []model.Project
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, "/projects", "").Body).Decode(&projects)
+
+		if len(projects) != 2 || projects[0].Name != "inbox" || projects[1].Name != "board" {
+			t.Errorf("got projects %v, want inbox before board", projects)
+		}
+	})
+}
+
+// Tests that projects and tasks created before positions existed are ranked in the order of their ids
+func TestPositionMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db := store.NewDatabaseConnection(path)
+
+	for _, name := range []string{"first", "second", "third"} {
+		err := db.DB.Exec("INSERT INTO tasks (name, priority, project_id) VALUES (?, 'none', 1)", name).Error
+		assertError(t, "insert legacy task", err)
+	}
+
+	db = store.NewDatabaseConnection(path)
+
+	tasks := db.GetAllProjectTasks(model.Project{Model: gorm.Model{ID: 1}}, model.TaskFilter{})
+	if len(tasks) != 3 || tasks[0].Name != "first" || tasks[2].Name != "third" || tasks[0].Position == "" || tasks[0].Position >= tasks[1].Position {
+		t.Errorf("got tasks %v, want first, second and third with ascending positions", tasks)
+	}
+}
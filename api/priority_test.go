@@ -0,0 +1,113 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+	"gorm.io/gorm"
+)
+
+// Tests for storing priorities and sorting tasks by priority
+func TestTaskPriorities(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "homework"}`)
+
+	tasks := []string{
+		`{"name": "art"}`,
+		`{"name": "math", "priority": "High", "deadline": "2021-06-02T12:00:00Z"}`,
+		`{"name": "physics", "priority": "urgent"}`,
+		`{"name": "biology", "priority": "high", "deadline": "2021-06-01T12:00:00Z"}`,
+		`{"name": "music", "priority": "low"}`,
+	}
+	for _, task := range tasks {
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/homework/tasks", task)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+	}
+
+	t.Run("Priorities are stored in lower case", func(t *testing.T) {
+		workspace := db.GetWorkspace(model.DefaultWorkspaceName)
+		task := db.GetTask(db.GetProject(workspace.ID, "homework"), "math")
+
+		assertResponseBody(t, string(task.Priority), "high")
+	})
+
+	t.Run("Sort by priority then deadline", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodGet, "/projects/homework/tasks?sort=priority", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		var got []model.Task
+		json.NewDecoder(response.Body).Decode(&got)
+
+		want := []string{"physics", "biology", "math", "music", "art"}
+		if len(got) != len(want) {
+			t.Fatalf("got %d tasks, want %d", len(got), len(want))
+		}
+		for i, task := range got {
+			assertResponseBody(t, task.Name, want[i])
+		}
+	})
+}
+
+// Tests for unknown priorities and sort orders
+func TestPriorityValidation(t *testing.T) {
+	server, _ := setupTaskTests()
+
+	t.Run("Unknown priorities are rejected", func(t *testing.T) {
+		request := newAuthenticatedRequest(http.MethodPost, "/projects/homework/tasks", bytes.NewBufferString(`{"name": "chemistry", "priority": "asap"}`))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		request = newAuthenticatedRequest(http.MethodPatch, "/projects/homework/tasks/math", bytes.NewBufferString(`{"priority": "asap"}`))
+		response = httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Unknown sort order", func(t *testing.T) {
+		request := newAuthenticatedRequest(http.MethodGet, "/projects/homework/tasks?sort=name", nil)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+// Tests that free-form priorities of existing tasks are normalized
+func TestPriorityMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db := store.NewDatabaseConnection(path)
+
+	for _, priority := range []string{"High", "urgent", "1", "", "whenever", "normal"} {
+		err := db.DB.Exec("INSERT INTO tasks (name, priority, project_id) VALUES (?, ?, 1)", priority, priority).Error
+		assertError(t, "insert legacy task", err)
+	}
+
+	db = store.NewDatabaseConnection(path)
+
+	want := map[string]model.Priority{
+		"High":     model.PriorityHigh,
+		"urgent":   model.PriorityUrgent,
+		"1":        model.PriorityLow,
+		"":         model.PriorityNone,
+		"whenever": model.PriorityNone,
+		"normal":   model.PriorityMedium,
+	}
+	for name, priority := range want {
+		task := db.GetTask(model.Project{Model: gorm.Model{ID: 1}}, name)
+		assertResponseBody(t, string(task.Priority), string(priority))
+	}
+}
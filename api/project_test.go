@@ -15,10 +15,11 @@ import (
 // Test setUp for all project tests
 func setUpProjectTests() (server *api.TodoStore, store *StubTodoStore) {
 	store = &StubTodoStore{
-		map[string]bool{
+		Projects: map[string]bool{
 			"homework": false,
 			"cleaning": true,
-		}, []stubTask{{}},
+		},
+		Tasks: []stubTask{{}},
 	}
 
 	// Uses the TodoStore with our StubTodoStore
@@ -31,7 +32,7 @@ func TestGetProject(t *testing.T) {
 	server, _ := setUpProjectTests()
 
 	t.Run("returns project homework", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodGet, "/projects/homework", nil)
+		request := newAuthenticatedRequest(http.MethodGet, "/projects/homework", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -44,7 +45,7 @@ func TestGetProject(t *testing.T) {
 	})
 
 	t.Run("returns project cleaning", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodGet, "/projects/cleaning", nil)
+		request := newAuthenticatedRequest(http.MethodGet, "/projects/cleaning", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -57,7 +58,7 @@ func TestGetProject(t *testing.T) {
 	})
 
 	t.Run("return 404 on not existing projects", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodGet, "/projects/laundry", nil)
+		request := newAuthenticatedRequest(http.MethodGet, "/projects/laundry", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -75,7 +76,7 @@ func TestPostProject(t *testing.T) {
 
 	t.Run("Creates new Project laundry", func(t *testing.T) {
 		requestBody := makeNewPostProjectBody(t, "laundry")
-		request, _ := http.NewRequest(http.MethodPost, "/projects", requestBody)
+		request := newAuthenticatedRequest(http.MethodPost, "/projects", requestBody)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -86,7 +87,7 @@ func TestPostProject(t *testing.T) {
 
 	t.Run("Try to create a project that already exists", func(t *testing.T) {
 		requestBody := makeNewPostProjectBody(t, "homework")
-		request, _ := http.NewRequest(http.MethodPost, "/projects", requestBody)
+		request := newAuthenticatedRequest(http.MethodPost, "/projects", requestBody)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -100,7 +101,7 @@ func TestGetAllProjects(t *testing.T) {
 	server, _ := setUpProjectTests()
 
 	t.Run("Get all stored projects", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodGet, "/projects", nil)
+		request := newAuthenticatedRequest(http.MethodGet, "/projects", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -129,7 +130,7 @@ func TestDeleteProject(t *testing.T) {
 	server, store := setUpProjectTests()
 
 	t.Run("Delete project homework", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodDelete, "/projects/homework", nil)
+		request := newAuthenticatedRequest(http.MethodDelete, "/projects/homework", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -147,7 +148,7 @@ func TestPutProject(t *testing.T) {
 	t.Run("Change the name of project homework", func(t *testing.T) {
 		projectName := "researchpaper"
 		requestBody := makeNewPostProjectBody(t, projectName)
-		request, _ := http.NewRequest(http.MethodPut, "/projects/homework", requestBody)
+		request := newAuthenticatedRequest(http.MethodPut, "/projects/homework", requestBody)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -166,7 +167,7 @@ func TestArchiveProject(t *testing.T) {
 	server, store := setUpProjectTests()
 
 	t.Run("Archive project homework", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodPut, "/projects/homework/archive", nil)
+		request := newAuthenticatedRequest(http.MethodPut, "/projects/homework/archive", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -176,7 +177,7 @@ func TestArchiveProject(t *testing.T) {
 	})
 
 	t.Run("Try to archive not existing project", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodPut, "/projects/researchpaper/archive", nil)
+		request := newAuthenticatedRequest(http.MethodPut, "/projects/researchpaper/archive", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -189,7 +190,7 @@ func TestUnArchiveProject(t *testing.T) {
 	server, store := setUpProjectTests()
 
 	t.Run("unarchive project homework", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodDelete, "/projects/cleaning/archive", nil)
+		request := newAuthenticatedRequest(http.MethodDelete, "/projects/cleaning/archive", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -199,7 +200,7 @@ func TestUnArchiveProject(t *testing.T) {
 	})
 
 	t.Run("Try to unarchive not existing project", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodDelete, "/projects/researchpaper/archive", nil)
+		request := newAuthenticatedRequest(http.MethodDelete, "/projects/researchpaper/archive", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -0,0 +1,123 @@
+// Package rank orders items by lexicographic ranks. A new rank can always be placed
+// between two others, so moving an item only changes the rank of that item.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+// Digits of ranks in ascending order
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var ErrOrder = errors.New("ranks are not in ascending order")
+
+// Returns a rank between a and b. An empty a is before all ranks, an empty b after all ranks.
+// Ranks only consist of digits and never end in the smallest digit, which keeps room below every rank
+func Between(a, b string) (string, error) {
+	if !valid(a) || !valid(b) || (b != "" && a >= b) {
+		return "", ErrOrder
+	}
+	return midpoint(a, b), nil
+}
+
+// Returns a rank after a. Increments the first digit that can be incremented and only adds
+// a digit once all are the largest, so ranks grow by one digit every 61 items appended at the end
+func After(a string) string {
+	if a == "" {
+		return midpoint("", "")
+	}
+
+	for i := 0; i < len(a); i++ {
+		if d := strings.IndexByte(digits, a[i]); d < len(digits)-1 {
+			return a[:i] + string(digits[d+1])
+		}
+	}
+	return a + digits[1:2]
+}
+
+// Returns n ascending ranks spread out evenly, used to rank existing items in one go
+func Spread(n int) []string {
+	base := len(digits)
+
+	// Enough digits to give every item its own value
+	width, total := 1, base
+	for total <= n {
+		width++
+		total *= base
+	}
+
+	ranks := make([]string, n)
+	for i := range ranks {
+		value := total / (n + 1) * (i + 1)
+
+		r := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			r[j] = digits[value%base]
+			value /= base
+		}
+		ranks[i] = strings.TrimRight(string(r), digits[:1])
+	}
+	return ranks
+}
+
+// Returns the rank halfway between a and b, which are valid and in order
+func midpoint(a, b string) string {
+	// Keep the common prefix, a is padded with the smallest digit
+	if b != "" {
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + midpoint(rest, b[n:])
+		}
+	}
+
+	da := 0
+	if a != "" {
+		da = strings.IndexByte(digits, a[0])
+	}
+	db := len(digits)
+	if b != "" {
+		db = strings.IndexByte(digits, b[0])
+	}
+
+	// There is a digit between the first digits
+	if db-da > 1 {
+		return string(digits[(da+db)/2])
+	}
+
+	// The first digits are consecutive. The first digit of a longer b is already between
+	if len(b) > 1 {
+		return b[:1]
+	}
+
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(digits[da]) + midpoint(rest, "")
+}
+
+// Returns the digit of the rank at the index or the smallest digit past its end
+func digitAt(r string, i int) byte {
+	if i < len(r) {
+		return r[i]
+	}
+	return digits[0]
+}
+
+// Reports whether the rank only consists of digits and does not end in the smallest digit
+func valid(r string) bool {
+	for i := 0; i < len(r); i++ {
+		if strings.IndexByte(digits, r[i]) < 0 {
+			return false
+		}
+	}
+	return !strings.HasSuffix(r, digits[:1])
+}
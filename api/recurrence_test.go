@@ -0,0 +1,199 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/rrule"
+)
+
+// Tests the occurrences of recurrence rules
+func TestRecurrenceRules(t *testing.T) {
+	// A monday
+	start := time.Date(2021, 5, 31, 9, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		rule string
+		want []string
+	}{
+		{"FREQ=DAILY;INTERVAL=2", []string{"2021-06-02", "2021-06-04", "2021-06-06"}},
+		{"FREQ=WEEKLY", []string{"2021-06-07", "2021-06-14", "2021-06-21"}},
+		{"FREQ=WEEKLY;BYDAY=WE,FR", []string{"2021-06-02", "2021-06-04", "2021-06-09"}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TU", []string{"2021-06-01", "2021-06-14", "2021-06-15"}},
+		{"FREQ=MONTHLY", []string{"2021-07-31", "2021-08-31", "2021-10-31"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", []string{"2021-06-30", "2021-07-31", "2021-08-31"}},
+		{"FREQ=MONTHLY;BYDAY=-1FR", []string{"2021-06-25", "2021-07-30", "2021-08-27"}},
+		{"FREQ=MONTHLY;BYDAY=2TU", []string{"2021-06-08", "2021-07-13", "2021-08-10"}},
+		{"FREQ=YEARLY", []string{"2022-05-31", "2023-05-31", "2024-05-31"}},
+		{"FREQ=DAILY;COUNT=3", []string{"2021-06-01", "2021-06-02"}},
+		{"FREQ=WEEKLY;UNTIL=20210614", []string{"2021-06-07", "2021-06-14"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.rule, func(t *testing.T) {
+			rule, err := rrule.Parse(c.rule)
+			assertError(t, "parse rule", err)
+
+			occurrences := rule.Occurrences(start, 3)
+			if len(occurrences) != len(c.want) {
+				t.Fatalf("got %v, want %v", occurrences, c.want)
+			}
+			for i, occurrence := range occurrences {
+				assertResponseBody(t, occurrence.Format("2006-01-02"), c.want[i])
+				if occurrence.Hour() != 9 {
+					t.Errorf("got time %v, want 9:00", occurrence)
+				}
+			}
+		})
+	}
+
+	t.Run("Rules are normalized", func(t *testing.T) {
+		rule, err := rrule.Parse("rrule:byday=mo;freq=weekly;interval=1")
+		assertError(t, "parse rule", err)
+
+		assertResponseBody(t, rule.String(), "FREQ=WEEKLY;BYDAY=MO")
+	})
+
+	t.Run("Invalid rules", func(t *testing.T) {
+		for _, value := range []string{"", "FREQ=HOURLY", "FREQ=DAILY;BYDAY=XY", "FREQ=WEEKLY;BYDAY=1MO", "FREQ=DAILY;COUNT=2;UNTIL=20210101", "FREQ=DAILY;BYSETPOS=1",
+			"FREQ=YEARLY;BYMONTHDAY=1", "FREQ=YEARLY;BYDAY=MO", "FREQ=WEEKLY;BYMONTHDAY=15"} {
+			if _, err := rrule.Parse(value); err == nil {
+				t.Errorf("rule %q should be invalid", value)
+			}
+		}
+	})
+}
+
+// Tests completing repeating tasks and previewing their occurrences
+func TestRecurringTasks(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	getTask := func(name string) model.Task {
+		workspace := db.GetWorkspace(model.DefaultWorkspaceName)
+		return db.GetTask(db.GetProject(workspace.ID, "chores"), name)
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "chores"}`)
+
+	t.Run("Completing creates the next occurrence", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, "/projects/chores/tasks", `{"name": "trash", "recurrence": "freq=weekly;byday=mo", "deadline": "2021-05-31T18:00:00Z"}`)
+		serveWithToken(server, alice, http.MethodPost, "/projects/chores/tasks/trash/subtasks", `{"name": "recycling"}`)
+		serveWithToken(server, alice, http.MethodPost, "/tags", `{"name": "weekly"}`)
+		serveWithToken(server, alice, http.MethodPost, "/projects/chores/tasks/trash/tags", `{"name": "weekly"}`)
+		serveWithToken(server, alice, http.MethodPost, "/projects/chores/tasks/trash/assignees", `{"user": "alice"}`)
+		serveWithToken(server, alice, http.MethodPost, "/projects/chores/tasks/trash/comments", `{"body": "Bins are in the yard"}`)
+		first := getTask("trash")
+
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/chores/tasks/trash/complete?subtasks=complete", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		task := getTask("trash")
+		if task.ID == first.ID || task.Done || task.Deadline == nil || !task.Deadline.Equal(time.Date(2021, 6, 7, 18, 0, 0, 0, time.UTC)) {
+			t.Errorf("got task %d done %v with deadline %v, want a new open task due 2021-06-07", task.ID, task.Done, task.Deadline)
+		}
+		assertResponseBody(t, task.Recurrence, "FREQ=WEEKLY;BYDAY=MO")
+		assertResponseBody(t, string(task.RepeatFrom), "due")
+
+		if len(task.Tags) != 1 || len(task.Assignees) != 1 || task.Position <= first.Position {
+			t.Errorf("got tags %v, assignees %v and position %q, want the tag, the assignee and a new position", task.Tags, task.Assignees, task.Position)
+		}
+		if comments := db.GetComments(task, model.CommentFilter{Limit: 10}); len(comments) != 0 {
+			t.Errorf("got comments %v, want none on the next occurrence", comments)
+		}
+
+		completed := getTask("trash (2021-05-31)")
+		if completed.ID != first.ID || !completed.Done || completed.Recurrence != "" {
+			t.Errorf("got completed occurrence %v, want the first task done without recurrence", completed)
+		}
+
+		recycling := getTask("recycling")
+		if recycling.Done || recycling.ParentID == nil || *recycling.ParentID != task.ID {
+			t.Error("subtask was not reopened for the next occurrence")
+		}
+	})
+
+	t.Run("Repeat from the completion date", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, "/projects/chores/tasks", `{"name": "water plants", "recurrence": "FREQ=DAILY;INTERVAL=3", "repeat_from": "completion", "deadline": "2021-01-01T08:00:00Z"}`)
+
+		serveWithToken(server, alice, http.MethodPut, "/projects/chores/tasks/water plants/complete", "")
+
+		deadline := getTask("water plants").Deadline
+		now := time.Now().UTC()
+		want := time.Date(now.Year(), now.Month(), now.Day()+3, 8, 0, 0, 0, time.UTC)
+		if deadline == nil || !deadline.Equal(want) {
+			t.Errorf("got deadline %v, want %v", deadline, want)
+		}
+	})
+
+	t.Run("Tasks are completed when the rule ends", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, "/projects/chores/tasks", `{"name": "vacuum", "recurrence": "FREQ=DAILY;COUNT=2", "deadline": "2021-06-01T10:00:00Z"}`)
+
+		serveWithToken(server, alice, http.MethodPut, "/projects/chores/tasks/vacuum/complete", "")
+		task := getTask("vacuum")
+		if task.Done {
+			t.Fatal("task was completed before its last occurrence")
+		}
+		assertResponseBody(t, task.Recurrence, "FREQ=DAILY;COUNT=1")
+
+		serveWithToken(server, alice, http.MethodPut, "/projects/chores/tasks/vacuum/complete", "")
+		if !getTask("vacuum").Done {
+			t.Error("task was not completed after its last occurrence")
+		}
+	})
+
+	t.Run("Preview the next occurrences", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodGet, "/projects/chores/tasks/trash/occurrences?count=3", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		var occurrences []time.Time
+		json.NewDecoder(response.Body).Decode(&occurrences)
+
+		want := []string{"2021-06-14", "2021-06-21", "2021-06-28"}
+		if len(occurrences) != len(want) {
+			t.Fatalf("got %v, want %v", occurrences, want)
+		}
+		for i, occurrence := range occurrences {
+			assertResponseBody(t, occurrence.Format("2006-01-02"), want[i])
+		}
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/chores/tasks/trash/occurrences?count=1000", "")
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/chores/tasks/recycling/occurrences", "")
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+// Tests for recurrences that are rejected when creating tasks
+func TestRecurrenceValidation(t *testing.T) {
+	server, _ := setupTaskTests()
+
+	t.Run("Invalid recurrences are rejected", func(t *testing.T) {
+		request := newAuthenticatedRequest(http.MethodPost, "/projects/homework/tasks", bytes.NewBufferString(`{"name": "dishes", "recurrence": "FREQ=SOMETIMES"}`))
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		request = newAuthenticatedRequest(http.MethodPost, "/projects/homework/tasks", bytes.NewBufferString(`{"name": "dishes", "recurrence": "FREQ=YEARLY;BYMONTHDAY=1"}`))
+		response = httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		request = newAuthenticatedRequest(http.MethodPost, "/projects/homework/tasks", bytes.NewBufferString(`{"name": "dishes", "recurrence": "FREQ=DAILY", "repeat_from": "tomorrow"}`))
+		response = httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
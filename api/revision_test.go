@@ -0,0 +1,109 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for the revision history of tasks and projects
+func TestRevisions(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	getHistory := func(url string) []model.Revision {
+		var revisions []model.Revision
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, url, "").Body).Decode(&revisions)
+		return revisions
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "house"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/house/tasks", `{"name": "paint", "deadline": "2030-06-01T12:00:00Z"}`)
+
+	t.Run("Updates record the prior state", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPatch, "/projects/house/tasks/paint", `{"name": "paint walls"}`)
+		serveWithToken(server, alice, http.MethodPatch, "/projects/house/tasks/paint walls", `{"deadline": "2030-07-01T12:00:00Z", "priority": "high"}`)
+		serveWithToken(server, alice, http.MethodPatch, "/projects/house/tasks/paint walls", `{"priority": "high"}`)
+
+		got := getHistory("/projects/house/tasks/paint walls/history")
+		if len(got) != 3 || got[0].Number != 3 || got[2].Number != 1 {
+			t.Fatalf("got revisions %v, want revisions 3 to 1", got)
+		}
+		assertResponseBody(t, string(got[2].Changed), `["name"]`)
+		assertResponseBody(t, string(got[1].Changed), `["priority","deadline"]`)
+		assertResponseBody(t, string(got[0].Changed), `[]`)
+
+		var snapshot model.Task
+		json.Unmarshal(got[2].Snapshot, &snapshot)
+		assertResponseBody(t, snapshot.Name, "paint")
+	})
+
+	t.Run("Restore a task to a revision", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/house/tasks/paint walls/history/1/restore", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+		assertResponseBody(t, response.Body.String(), `{"message":"Task paint restored to revision 1"}`+"\n")
+
+		var task model.Task
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, "/projects/house/tasks/paint", "").Body).Decode(&task)
+		if task.Deadline == nil || task.Deadline.Month() != 6 || task.Priority != model.PriorityNone {
+			t.Errorf("got task %v, want the deadline and priority of revision 1", task)
+		}
+
+		if got := getHistory("/projects/house/tasks/paint/history"); len(got) != 4 {
+			t.Errorf("got %d revisions, want the restore recorded as revision 4", len(got))
+		}
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects/house/tasks/paint/history/9/restore", "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("Restoring a name taken by another task fails", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPatch, "/projects/house/tasks/paint", `{"name": "paint doors"}`)
+		serveWithToken(server, alice, http.MethodPost, "/projects/house/tasks", `{"name": "paint"}`)
+
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/house/tasks/paint doors/history/5/restore", "")
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Restored revisions are validated like updates", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, "/projects/house/tasks", `{"name": "sand"}`)
+		serveWithToken(server, alice, http.MethodPatch, "/projects/house/tasks/sand", `{"name": "sand floor"}`)
+
+		// Revisions of older versions may hold values that are no longer valid
+		sand := db.GetTask(db.GetProject(db.GetWorkspace(model.DefaultWorkspaceName).ID, "house"), "sand floor")
+		err := db.DB.Exec("UPDATE revisions SET snapshot = ? WHERE resource_id = ? AND number = 1", `{"name": "sand", "priority": "someday"}`, sand.ID).Error
+		if err != nil {
+			t.Fatalf("could not change the snapshot: %v", err)
+		}
+
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/house/tasks/sand floor/history/1/restore", "")
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Completed tasks keep their recurrence", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, "/projects/house/tasks", `{"name": "water"}`)
+		serveWithToken(server, alice, http.MethodPatch, "/projects/house/tasks/water", `{"recurrence": "FREQ=DAILY"}`)
+		serveWithToken(server, alice, http.MethodPatch, "/projects/house/tasks/water", `{"description": "the plants"}`)
+		serveWithToken(server, alice, http.MethodPatch, "/projects/house/tasks/water", `{"recurrence": ""}`)
+		serveWithToken(server, alice, http.MethodPut, "/projects/house/tasks/water/complete", "")
+
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/house/tasks/water/history/2/restore", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		water := db.GetTask(db.GetProject(db.GetWorkspace(model.DefaultWorkspaceName).ID, "house"), "water")
+		if !water.Done || water.Recurrence != "" {
+			t.Errorf("got task %v, want it completed without recurrence", water)
+		}
+	})
+
+	t.Run("Project updates are recorded", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPut, "/projects/house", `{"name": "home"}`)
+
+		got := getHistory("/projects/home/history")
+		if len(got) != 1 || string(got[0].Changed) != `["name"]` {
+			t.Errorf("got revisions %v, want the rename", got)
+		}
+	})
+}
@@ -0,0 +1,394 @@
+// Package rrule parses recurrence rules of RFC 5545 and computes their next occurrences.
+// Supported are FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT and UNTIL. Yearly rules repeat on the
+// day of the first occurrence and take neither BYDAY nor BYMONTHDAY, weekly rules take no BYMONTHDAY.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequencies of a rule
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+	Yearly  = "YEARLY"
+)
+
+// Number of periods searched for the next occurrence, enough
+// to find a 29th of February or a 31st with any interval
+const maxPeriods = 5000
+
+var (
+	ErrFrequency     = errors.New("FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY")
+	ErrCountAndUntil = errors.New("COUNT and UNTIL can not be combined")
+)
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Weekday of BYDAY. N selects the nth weekday of the month, negative values count
+// from the end of the month and 0 selects every such weekday
+type Weekday struct {
+	Day time.Weekday
+	N   int
+}
+
+func (d Weekday) String() string {
+	day := strings.ToUpper(d.Day.String()[:2])
+	if d.N == 0 {
+		return day
+	}
+	return strconv.Itoa(d.N) + day
+}
+
+// A recurrence rule like FREQ=WEEKLY;BYDAY=MO
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []Weekday
+	ByMonthDay []int
+
+	// Number of occurrences including the first one, unlimited if 0
+	Count int
+
+	// Last possible occurrence, unlimited if zero
+	Until time.Time
+}
+
+// Parses a rule, an optional RRULE: prefix is ignored
+func Parse(value string) (Rule, error) {
+	rule := Rule{Interval: 1}
+
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(strings.ToUpper(value), "RRULE:") {
+		value = value[len("RRULE:"):]
+	}
+	for _, part := range strings.Split(value, ";") {
+		pair := strings.SplitN(part, "=", 2)
+		if len(pair) != 2 {
+			return Rule{}, fmt.Errorf("invalid rule part %q", part)
+		}
+		name, value := strings.ToUpper(pair[0]), strings.ToUpper(pair[1])
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = value
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err == nil && rule.Interval < 1 {
+				err = errors.New("INTERVAL must be positive")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+			if err == nil && rule.Count < 1 {
+				err = errors.New("COUNT must be positive")
+			}
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseByMonthDay(value)
+		default:
+			err = fmt.Errorf("%v is not supported", name)
+		}
+
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid %v: %v", name, err)
+		}
+	}
+
+	switch rule.Freq {
+	case Daily, Weekly, Monthly, Yearly:
+	default:
+		return Rule{}, ErrFrequency
+	}
+
+	if rule.Count != 0 && !rule.Until.IsZero() {
+		return Rule{}, ErrCountAndUntil
+	}
+
+	// The days of yearly and weekly periods are not filtered, the rule would be ignored
+	if rule.Freq == Yearly && (len(rule.ByDay) > 0 || len(rule.ByMonthDay) > 0) {
+		return Rule{}, errors.New("BYDAY and BYMONTHDAY are not supported with FREQ=YEARLY")
+	}
+	if rule.Freq == Weekly && len(rule.ByMonthDay) > 0 {
+		return Rule{}, errors.New("BYMONTHDAY is not supported with FREQ=WEEKLY")
+	}
+
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly {
+			return Rule{}, errors.New("numbered BYDAY values are only supported with FREQ=MONTHLY")
+		}
+	}
+
+	return rule, nil
+}
+
+// Formats the rule in the order FREQ, INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Returns the first occurrence after the given time. The occurrences keep the time of day and location
+// of after. Returns false if the rule ends before. COUNT is not considered, see Occurrences
+func (r Rule) Next(after time.Time) (time.Time, bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	period := r.periodStart(after)
+	for i := 0; i < maxPeriods; i++ {
+		for _, candidate := range r.candidates(period, after) {
+			if !r.Until.IsZero() && candidate.After(r.Until) {
+				return time.Time{}, false
+			}
+			if candidate.After(after) {
+				return candidate, true
+			}
+		}
+		period = r.addPeriods(period, interval)
+	}
+
+	return time.Time{}, false
+}
+
+// Returns up to n occurrences after start. Start counts as first occurrence for COUNT
+func (r Rule) Occurrences(start time.Time, n int) []time.Time {
+	occurrences := []time.Time{}
+
+	if r.Count > 0 && n > r.Count-1 {
+		n = r.Count - 1
+	}
+
+	next := start
+	for len(occurrences) < n {
+		var ok bool
+		next, ok = r.Next(next)
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, next)
+	}
+	return occurrences
+}
+
+// Returns the first day of the period containing t at the time of day of t
+func (r Rule) periodStart(t time.Time) time.Time {
+	switch r.Freq {
+	case Weekly:
+		// Weeks start on monday
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset)
+	case Monthly:
+		return t.AddDate(0, 0, 1-t.Day())
+	case Yearly:
+		return t.AddDate(0, 0, 1-t.YearDay())
+	}
+	return t
+}
+
+func (r Rule) addPeriods(period time.Time, n int) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return period.AddDate(0, 0, 7*n)
+	case Monthly:
+		return period.AddDate(0, n, 0)
+	case Yearly:
+		return period.AddDate(n, 0, 0)
+	}
+	return period.AddDate(0, 0, n)
+}
+
+// Returns the occurrences in the period in ascending order. Without BYDAY and BYMONTHDAY
+// the weekday, day of month or day of year of start is used
+func (r Rule) candidates(period, start time.Time) []time.Time {
+	days := []time.Time{}
+
+	switch r.Freq {
+	case Daily:
+		days = append(days, period)
+	case Weekly:
+		for i := 0; i < 7; i++ {
+			day := period.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && day.Weekday() == start.Weekday() {
+				days = append(days, day)
+			}
+		}
+		if len(r.ByDay) > 0 {
+			days = r.daysOfPeriod(period, 7)
+		}
+	case Monthly:
+		length := period.AddDate(0, 1, 0).Sub(period).Hours() / 24
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			if start.Day() <= int(length+0.5) {
+				days = append(days, period.AddDate(0, 0, start.Day()-1))
+			}
+		} else {
+			days = r.daysOfPeriod(period, int(length+0.5))
+		}
+	case Yearly:
+		// The same month and day as start, skipped in years without that day
+		day := time.Date(period.Year(), start.Month(), start.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		if day.Month() == start.Month() {
+			days = append(days, day)
+		}
+	}
+
+	// BYDAY and BYMONTHDAY limit the days of DAILY rules
+	if r.Freq == Daily {
+		days = r.filter(days, 0)
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// Returns the days of the period matching BYDAY and BYMONTHDAY
+func (r Rule) daysOfPeriod(period time.Time, length int) []time.Time {
+	days := []time.Time{}
+	for i := 0; i < length; i++ {
+		days = append(days, period.AddDate(0, 0, i))
+	}
+	return r.filter(days, length)
+}
+
+// Keeps the days matching BYDAY and BYMONTHDAY. Numbered weekdays are counted within the
+// days, which have to be a whole month. monthLength is 0 for other periods
+func (r Rule) filter(days []time.Time, monthLength int) []time.Time {
+	matching := []time.Time{}
+
+	for i, day := range days {
+		if len(r.ByDay) > 0 && !r.matchesByDay(day, i, monthLength) {
+			continue
+		}
+		if len(r.ByMonthDay) > 0 && !r.matchesByMonthDay(day) {
+			continue
+		}
+		matching = append(matching, day)
+	}
+	return matching
+}
+
+func (r Rule) matchesByDay(day time.Time, index, monthLength int) bool {
+	for _, weekday := range r.ByDay {
+		if weekday.Day != day.Weekday() {
+			continue
+		}
+		if weekday.N == 0 {
+			return true
+		}
+		if weekday.N > 0 && index/7+1 == weekday.N {
+			return true
+		}
+		if weekday.N < 0 && monthLength > 0 && -((monthLength-1-index)/7+1) == weekday.N {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matchesByMonthDay(day time.Time) bool {
+	lastDay := day.AddDate(0, 1, -day.Day()).Day()
+
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay == day.Day() || monthDay < 0 && lastDay+monthDay+1 == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if until, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// The whole last day is included
+				until = until.Add(24*time.Hour - time.Second)
+			}
+			return until, nil
+		}
+	}
+	return time.Time{}, errors.New("expected a date like 20210131 or 20210131T120000Z")
+}
+
+func parseByDay(value string) ([]Weekday, error) {
+	days := []Weekday{}
+
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("unknown weekday %q", item)
+		}
+
+		day, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", item)
+		}
+
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid weekday number in %q", item)
+			}
+		}
+
+		days = append(days, Weekday{Day: day, N: n})
+	}
+	return days, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	days := []int{}
+
+	for _, item := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(item)
+		if err != nil || day == 0 || day < -31 || day > 31 {
+			return nil, fmt.Errorf("invalid day of month %q", item)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
@@ -5,12 +5,19 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/mpfen/Go-Todo-REST-API/api/handler"
+	"github.com/mpfen/Go-Todo-REST-API/api/oidc"
 	"github.com/mpfen/Go-Todo-REST-API/api/store"
 )
 
 type TodoStore struct {
 	Router *mux.Router
 	Store  store.TodoStore
+
+	// Validates JWTs of an identity provider. JWT authentication is disabled if nil
+	OIDC *oidc.Validator
+
+	// Stores the files of attachments. Uploads and downloads are disabled if nil
+	Attachments *handler.AttachmentStorage
 }
 
 // Initalize TodoStore and create a gorilla/mux Router
@@ -20,25 +27,199 @@ func NewTodoStore(store store.TodoStore) *TodoStore {
 
 	p.Router = mux.NewRouter()
 
-	// Project routes
-	p.Router.HandleFunc("/projects", p.PostProject).Methods("POST")
-	p.Router.HandleFunc("/projects", p.GetAllProjects).Methods("GET")
-	p.Router.HandleFunc("/projects/{name}", p.GetProject).Methods("GET")
-	p.Router.HandleFunc("/projects/{name}", p.DeleteProject).Methods("DELETE")
-	p.Router.HandleFunc("/projects/{name}", p.UpdateProject).Methods("PUT")
-	p.Router.HandleFunc("/projects/{name}/archive", p.ArchiveProject).Methods("PUT", "DELETE")
-
-	// Task routes
-	p.Router.HandleFunc("/projects/{projectName}/tasks/{taskName}", p.GetTask).Methods("GET")
-	p.Router.HandleFunc("/projects/{projectName}/tasks", p.PostTask).Methods("POST")
-	p.Router.HandleFunc("/projects/{projectName}/tasks", p.GetAllProjectTasks).Methods("GET")
-	p.Router.HandleFunc("/projects/{projectName}/tasks/{taskName}", p.DeleteTask).Methods("DELETE")
-	p.Router.HandleFunc("/projects/{projectName}/tasks/{taskName}", p.UpdateTask).Methods("PUT")
-	p.Router.HandleFunc("/projects/{projectName}/tasks/{taskName}/complete", p.CompleteTask).Methods("PUT", "DELETE")
+	// User routes
+	p.Router.HandleFunc("/users", p.PostUser).Methods("POST")
+	p.Router.HandleFunc("/login", p.Login).Methods("POST")
+
+	// All other routes require a bearer token
+	router := p.Router.NewRoute().Subrouter()
+	router.Use(p.AuthenticateOIDC, p.Authenticate, handler.ScopeMiddleware)
+
+	router.HandleFunc("/logout", p.Logout).Methods("POST")
+	router.HandleFunc("/me", p.GetCurrentUser).Methods("GET")
+	router.HandleFunc("/me/tasks", p.GetMyTasks).Methods("GET")
+
+	// API key routes
+	router.HandleFunc("/api-keys", p.PostAPIKey).Methods("POST")
+	router.HandleFunc("/api-keys", p.GetAllAPIKeys).Methods("GET")
+	router.HandleFunc("/api-keys/{id:[0-9]+}", p.DeleteAPIKey).Methods("DELETE")
+
+	// Audit log routes
+	router.HandleFunc("/audit", p.GetAudit).Methods("GET")
+
+	// Trash routes
+	router.HandleFunc("/trash", p.GetTrash).Methods("GET")
+	router.HandleFunc("/trash/projects/{id:[0-9]+}/restore", p.RestoreProject).Methods("POST")
+	router.HandleFunc("/trash/projects/{id:[0-9]+}", p.PurgeProject).Methods("DELETE")
+	router.HandleFunc("/trash/tasks/{id:[0-9]+}/restore", p.RestoreTask).Methods("POST")
+	router.HandleFunc("/trash/tasks/{id:[0-9]+}", p.PurgeTask).Methods("DELETE")
+
+	// Workspace routes
+	router.HandleFunc("/workspaces", p.PostWorkspace).Methods("POST")
+	router.HandleFunc("/workspaces", p.GetAllWorkspaces).Methods("GET")
+	router.HandleFunc("/workspaces/{workspace}", p.GetWorkspace).Methods("GET")
+	router.HandleFunc("/workspaces/{workspace}/members", p.GetWorkspaceMembers).Methods("GET")
+	router.HandleFunc("/workspaces/{workspace}/members", p.PostWorkspaceMember).Methods("POST")
+	router.HandleFunc("/workspaces/{workspace}/members/{userName}", p.UpdateWorkspaceMember).Methods("PUT")
+	router.HandleFunc("/workspaces/{workspace}/members/{userName}", p.DeleteWorkspaceMember).Methods("DELETE")
+
+	// Project and tag lists exist for the default workspace and every other workspace
+	for _, prefix := range []string{"", "/workspaces/{workspace}"} {
+		router.HandleFunc(prefix+"/projects", p.PostProject).Methods("POST")
+		router.HandleFunc(prefix+"/projects", p.GetAllProjects).Methods("GET")
+
+		// Tag routes
+		router.HandleFunc(prefix+"/tags", p.PostTag).Methods("POST")
+		router.HandleFunc(prefix+"/tags", p.GetAllTags).Methods("GET")
+		router.HandleFunc(prefix+"/tags/{tagName}", p.GetTag).Methods("GET")
+		router.HandleFunc(prefix+"/tags/{tagName}", p.UpdateTag).Methods("PUT")
+		router.HandleFunc(prefix+"/tags/{tagName}", p.DeleteTag).Methods("DELETE")
+	}
+
+	// Projects are addressed by id or by name in the default workspace or another workspace.
+	// The id routes come first, numeric project names are not allowed
+	tasks := []string{"/tasks/{taskID:[0-9]+}"}
+	for _, project := range []string{"/projects/{projectID:[0-9]+}", "/projects/{name}", "/workspaces/{workspace}/projects/{name}"} {
+		// Project routes
+		router.HandleFunc(project, p.GetProject).Methods("GET")
+		router.HandleFunc(project, p.DeleteProject).Methods("DELETE")
+		router.HandleFunc(project, p.UpdateProject).Methods("PUT")
+		router.HandleFunc(project+"/archive", p.ArchiveProject).Methods("PUT", "DELETE")
+		router.HandleFunc(project+"/summary", p.GetProjectSummary).Methods("GET")
+		router.HandleFunc(project+"/history", p.GetProjectHistory).Methods("GET")
+		router.HandleFunc(project+"/position", p.MoveProject).Methods("PUT")
+		router.HandleFunc(project+"/time", p.GetProjectTime).Methods("GET")
+
+		// Member routes
+		router.HandleFunc(project+"/members", p.GetProjectMembers).Methods("GET")
+		router.HandleFunc(project+"/members", p.PostProjectMember).Methods("POST")
+		router.HandleFunc(project+"/members/{userName}", p.UpdateProjectMember).Methods("PUT")
+		router.HandleFunc(project+"/members/{userName}", p.DeleteProjectMember).Methods("DELETE")
+
+		// Task routes
+		router.HandleFunc(project+"/tasks", p.PostTask).Methods("POST")
+		router.HandleFunc(project+"/tasks", p.GetAllProjectTasks).Methods("GET")
+
+		tasks = append(tasks, project+"/tasks/{taskName}")
+	}
+
+	// Tasks are addressed by id or by name in a project
+	for _, task := range tasks {
+		router.HandleFunc(task, p.GetTask).Methods("GET")
+		router.HandleFunc(task, p.DeleteTask).Methods("DELETE")
+		router.HandleFunc(task, p.UpdateTask).Methods("PUT")
+		router.HandleFunc(task, p.PatchTask).Methods("PATCH")
+		router.HandleFunc(task+"/complete", p.CompleteTask).Methods("PUT", "DELETE")
+		router.HandleFunc(task+"/history", p.GetTaskHistory).Methods("GET")
+		router.HandleFunc(task+"/history/{rev:[0-9]+}/restore", p.RestoreTaskRevision).Methods("POST")
+		router.HandleFunc(task+"/move", p.MoveTaskToProject).Methods("POST")
+		router.HandleFunc(task+"/position", p.MoveTask).Methods("PUT")
+		router.HandleFunc(task+"/snooze", p.SnoozeTask).Methods("PUT", "DELETE")
+		router.HandleFunc(task+"/subtasks", p.GetSubtasks).Methods("GET")
+		router.HandleFunc(task+"/subtasks", p.PostSubtask).Methods("POST")
+		router.HandleFunc(task+"/blockers", p.PostBlocker).Methods("POST")
+		router.HandleFunc(task+"/blockers/{blockerID:[0-9]+}", p.DeleteBlocker).Methods("DELETE")
+		router.HandleFunc(task+"/occurrences", p.GetOccurrences).Methods("GET")
+		router.HandleFunc(task+"/tags", p.PostTaskTag).Methods("POST")
+		router.HandleFunc(task+"/tags/{tagName}", p.DeleteTaskTag).Methods("DELETE")
+		router.HandleFunc(task+"/comments", p.GetComments).Methods("GET")
+		router.HandleFunc(task+"/comments", p.PostComment).Methods("POST")
+		router.HandleFunc(task+"/comments/{commentID:[0-9]+}", p.UpdateComment).Methods("PUT")
+		router.HandleFunc(task+"/comments/{commentID:[0-9]+}", p.DeleteComment).Methods("DELETE")
+		router.HandleFunc(task+"/attachments", p.GetAttachments).Methods("GET")
+		router.HandleFunc(task+"/attachments", p.PostAttachment).Methods("POST")
+		router.HandleFunc(task+"/attachments/{attachmentID:[0-9]+}", p.GetAttachment).Methods("GET")
+		router.HandleFunc(task+"/attachments/{attachmentID:[0-9]+}", p.DeleteAttachment).Methods("DELETE")
+		router.HandleFunc(task+"/checklist", p.GetChecklist).Methods("GET")
+		router.HandleFunc(task+"/checklist", p.PostChecklistItem).Methods("POST")
+		router.HandleFunc(task+"/checklist", p.ReorderChecklist).Methods("PUT")
+		router.HandleFunc(task+"/checklist/{itemID:[0-9]+}", p.UpdateChecklistItem).Methods("PUT")
+		router.HandleFunc(task+"/checklist/{itemID:[0-9]+}", p.DeleteChecklistItem).Methods("DELETE")
+		router.HandleFunc(task+"/checklist/{itemID:[0-9]+}/check", p.CheckChecklistItem).Methods("PUT", "DELETE")
+		router.HandleFunc(task+"/assignees", p.PostTaskAssignee).Methods("POST")
+		router.HandleFunc(task+"/assignees/{userName}", p.DeleteTaskAssignee).Methods("DELETE")
+		router.HandleFunc(task+"/timer/start", p.StartTimer).Methods("POST")
+		router.HandleFunc(task+"/timer/stop", p.StopTimer).Methods("POST")
+		router.HandleFunc(task+"/time", p.GetTimeEntries).Methods("GET")
+		router.HandleFunc(task+"/time", p.PostTimeEntry).Methods("POST")
+	}
 
 	return p
 }
 
+// Middleware
+func (p *TodoStore) Authenticate(next http.Handler) http.Handler {
+	return handler.AuthenticationMiddleware(p.Store, next)
+}
+
+func (p *TodoStore) AuthenticateOIDC(next http.Handler) http.Handler {
+	return handler.OIDCMiddleware(p.Store, p.OIDC, next)
+}
+
+// User Handler
+func (p *TodoStore) PostUser(w http.ResponseWriter, r *http.Request) {
+	handler.PostUserHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) Login(w http.ResponseWriter, r *http.Request) {
+	handler.LoginHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) Logout(w http.ResponseWriter, r *http.Request) {
+	handler.LogoutHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	handler.GetCurrentUserHandler(p.Store, w, r)
+}
+
+// API key Handler
+func (p *TodoStore) PostAPIKey(w http.ResponseWriter, r *http.Request) {
+	handler.PostAPIKeyHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetAllAPIKeys(w http.ResponseWriter, r *http.Request) {
+	handler.GetAllAPIKeysHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	handler.DeleteAPIKeyHandler(p.Store, w, r)
+}
+
+// Audit Handler
+func (p *TodoStore) GetAudit(w http.ResponseWriter, r *http.Request) {
+	handler.GetAuditHandler(p.Store, w, r)
+}
+
+// Workspace Handler
+func (p *TodoStore) GetWorkspace(w http.ResponseWriter, r *http.Request) {
+	handler.GetWorkspaceHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostWorkspace(w http.ResponseWriter, r *http.Request) {
+	handler.PostWorkspaceHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetAllWorkspaces(w http.ResponseWriter, r *http.Request) {
+	handler.GetAllWorkspacesHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetWorkspaceMembers(w http.ResponseWriter, r *http.Request) {
+	handler.GetWorkspaceMembersHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	handler.PostWorkspaceMemberHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) UpdateWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	handler.UpdateWorkspaceMemberHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) DeleteWorkspaceMember(w http.ResponseWriter, r *http.Request) {
+	handler.DeleteWorkspaceMemberHandler(p.Store, w, r)
+}
+
 // Project Handler
 func (p *TodoStore) GetProject(w http.ResponseWriter, r *http.Request) {
 	handler.GetProjectHandler(p.Store, w, r)
@@ -64,6 +245,23 @@ func (p *TodoStore) ArchiveProject(w http.ResponseWriter, r *http.Request) {
 	handler.ArchiveProjectHandler(p.Store, w, r)
 }
 
+// Member Handler
+func (p *TodoStore) GetProjectMembers(w http.ResponseWriter, r *http.Request) {
+	handler.GetProjectMembersHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostProjectMember(w http.ResponseWriter, r *http.Request) {
+	handler.PostProjectMemberHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) UpdateProjectMember(w http.ResponseWriter, r *http.Request) {
+	handler.UpdateProjectMemberHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) DeleteProjectMember(w http.ResponseWriter, r *http.Request) {
+	handler.DeleteProjectMemberHandler(p.Store, w, r)
+}
+
 // Task Handler
 
 func (p *TodoStore) GetTask(w http.ResponseWriter, r *http.Request) {
@@ -86,6 +284,199 @@ func (p *TodoStore) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	handler.UpdateTaskHandler(p.Store, w, r)
 }
 
+func (p *TodoStore) PatchTask(w http.ResponseWriter, r *http.Request) {
+	handler.PatchTaskHandler(p.Store, w, r)
+}
+
 func (p *TodoStore) CompleteTask(w http.ResponseWriter, r *http.Request) {
 	handler.CompleteTaskHandler(p.Store, w, r)
 }
+
+func (p *TodoStore) GetSubtasks(w http.ResponseWriter, r *http.Request) {
+	handler.GetSubtasksHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostSubtask(w http.ResponseWriter, r *http.Request) {
+	handler.PostSubtaskHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostBlocker(w http.ResponseWriter, r *http.Request) {
+	handler.PostBlockerHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) DeleteBlocker(w http.ResponseWriter, r *http.Request) {
+	handler.DeleteBlockerHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetOccurrences(w http.ResponseWriter, r *http.Request) {
+	handler.GetOccurrencesHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetAllTags(w http.ResponseWriter, r *http.Request) {
+	handler.GetAllTagsHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetTag(w http.ResponseWriter, r *http.Request) {
+	handler.GetTagHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostTag(w http.ResponseWriter, r *http.Request) {
+	handler.PostTagHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	handler.UpdateTagHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	handler.DeleteTagHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostTaskTag(w http.ResponseWriter, r *http.Request) {
+	handler.PostTaskTagHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) DeleteTaskTag(w http.ResponseWriter, r *http.Request) {
+	handler.DeleteTaskTagHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetComments(w http.ResponseWriter, r *http.Request) {
+	handler.GetCommentsHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostComment(w http.ResponseWriter, r *http.Request) {
+	handler.PostCommentHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	handler.UpdateCommentHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	handler.DeleteCommentHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	handler.GetAttachmentsHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostAttachment(w http.ResponseWriter, r *http.Request) {
+	handler.PostAttachmentHandler(p.Store, p.Attachments, w, r)
+}
+
+func (p *TodoStore) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	handler.GetAttachmentHandler(p.Store, p.Attachments, w, r)
+}
+
+func (p *TodoStore) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	handler.DeleteAttachmentHandler(p.Store, p.Attachments, w, r)
+}
+
+func (p *TodoStore) GetChecklist(w http.ResponseWriter, r *http.Request) {
+	handler.GetChecklistHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostChecklistItem(w http.ResponseWriter, r *http.Request) {
+	handler.PostChecklistItemHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) ReorderChecklist(w http.ResponseWriter, r *http.Request) {
+	handler.ReorderChecklistHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) UpdateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	handler.UpdateChecklistItemHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) CheckChecklistItem(w http.ResponseWriter, r *http.Request) {
+	handler.CheckChecklistItemHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) DeleteChecklistItem(w http.ResponseWriter, r *http.Request) {
+	handler.DeleteChecklistItemHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) StartTimer(w http.ResponseWriter, r *http.Request) {
+	handler.StartTimerHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) StopTimer(w http.ResponseWriter, r *http.Request) {
+	handler.StopTimerHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetTimeEntries(w http.ResponseWriter, r *http.Request) {
+	handler.GetTimeEntriesHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostTimeEntry(w http.ResponseWriter, r *http.Request) {
+	handler.PostTimeEntryHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetProjectTime(w http.ResponseWriter, r *http.Request) {
+	handler.GetProjectTimeHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetProjectSummary(w http.ResponseWriter, r *http.Request) {
+	handler.GetProjectSummaryHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PostTaskAssignee(w http.ResponseWriter, r *http.Request) {
+	handler.PostTaskAssigneeHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) DeleteTaskAssignee(w http.ResponseWriter, r *http.Request) {
+	handler.DeleteTaskAssigneeHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetMyTasks(w http.ResponseWriter, r *http.Request) {
+	handler.GetMyTasksHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) MoveTask(w http.ResponseWriter, r *http.Request) {
+	handler.MoveTaskHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) MoveProject(w http.ResponseWriter, r *http.Request) {
+	handler.MoveProjectHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) MoveTaskToProject(w http.ResponseWriter, r *http.Request) {
+	handler.MoveTaskToProjectHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetProjectHistory(w http.ResponseWriter, r *http.Request) {
+	handler.GetProjectHistoryHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	handler.GetTaskHistoryHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) RestoreTaskRevision(w http.ResponseWriter, r *http.Request) {
+	handler.RestoreTaskRevisionHandler(p.Store, w, r)
+}
+
+// Trash Handler
+func (p *TodoStore) GetTrash(w http.ResponseWriter, r *http.Request) {
+	handler.GetTrashHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) RestoreProject(w http.ResponseWriter, r *http.Request) {
+	handler.RestoreProjectHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PurgeProject(w http.ResponseWriter, r *http.Request) {
+	handler.PurgeProjectHandler(p.Store, p.Attachments, w, r)
+}
+
+func (p *TodoStore) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	handler.RestoreTaskHandler(p.Store, w, r)
+}
+
+func (p *TodoStore) PurgeTask(w http.ResponseWriter, r *http.Request) {
+	handler.PurgeTaskHandler(p.Store, p.Attachments, w, r)
+}
+
+func (p *TodoStore) SnoozeTask(w http.ResponseWriter, r *http.Request) {
+	handler.SnoozeTaskHandler(p.Store, w, r)
+}
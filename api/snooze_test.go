@@ -0,0 +1,136 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for start dates, scheduled dates and snoozing tasks
+func TestSnooze(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	names := func(url string) []string {
+		var tasks []model.Task
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, url, "").Body).Decode(&tasks)
+
+		got := []string{}
+		for _, task := range tasks {
+			got = append(got, task.Name)
+		}
+		return got
+	}
+
+	getTask := func(url string) model.Task {
+		var task model.Task
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, url, "").Body).Decode(&task)
+		return task
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "garage"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/garage/tasks", `{"name": "tires", "start_at": "2099-10-01T08:00:00Z", "scheduled_for": "2099-10-03T00:00:00Z"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/garage/tasks", `{"name": "sweep"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/garage/tasks/sweep/subtasks", `{"name": "buy broom"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/garage/tasks/tires/assignees", `{"user": "alice"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/garage/tasks/sweep/assignees", `{"user": "alice"}`)
+
+	t.Run("Lists hide tasks that have not started", func(t *testing.T) {
+		if got := names("/projects/garage/tasks"); len(got) != 2 || got[0] != "sweep" {
+			t.Errorf("got tasks %v, want sweep and buy broom", got)
+		}
+		if got := names("/projects/garage/tasks?include_future=true"); len(got) != 3 {
+			t.Errorf("got tasks %v, want all three", got)
+		}
+		if got := names("/me/tasks"); len(got) != 1 || got[0] != "sweep" {
+			t.Errorf("got tasks %v, want sweep", got)
+		}
+
+		response := serveWithToken(server, alice, http.MethodGet, "/projects/garage/tasks?include_future=maybe", "")
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		task := getTask("/projects/garage/tasks/tires")
+		if task.ScheduledFor == nil || task.ScheduledFor.Day() != 3 {
+			t.Errorf("got scheduled date %v, want October 3", task.ScheduledFor)
+		}
+	})
+
+	t.Run("Snooze a task for a duration or until a time", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/garage/tasks/buy broom/snooze", `{"for": "3d"}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if got := names("/projects/garage/tasks/sweep/subtasks"); len(got) != 0 {
+			t.Errorf("got subtasks %v, want buy broom hidden", got)
+		}
+
+		start := getTask("/projects/garage/tasks/buy broom").StartAt
+		if start == nil || start.Before(time.Now().Add(71*time.Hour)) {
+			t.Errorf("got start %v, want in three days", start)
+		}
+
+		response = serveWithToken(server, alice, http.MethodPut, "/projects/garage/tasks/tires/snooze", `{"for": "2h"}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if start := getTask("/projects/garage/tasks/tires").StartAt; start == nil || start.UTC().Hour() != 10 {
+			t.Errorf("got start %v, want two hours after the previous start", start)
+		}
+
+		until := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		response = serveWithToken(server, alice, http.MethodPut, "/projects/garage/tasks/sweep/snooze", fmt.Sprintf(`{"until": %q}`, until))
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if got := names("/projects/garage/tasks"); len(got) != 0 {
+			t.Errorf("got tasks %v, want all snoozed", got)
+		}
+	})
+
+	t.Run("Unsnooze a task", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodDelete, "/projects/garage/tasks/sweep/snooze", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if got := names("/projects/garage/tasks"); len(got) != 1 || got[0] != "sweep" {
+			t.Errorf("got tasks %v, want sweep", got)
+		}
+	})
+
+	t.Run("Repeating tasks keep the distance of their start to the deadline", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, "/projects/garage/tasks", `{"name": "oil", "deadline": "2030-01-10T12:00:00Z", "start_at": "2030-01-08T12:00:00Z", "recurrence": "FREQ=WEEKLY"}`)
+
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/garage/tasks/oil/complete", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		task := getTask("/projects/garage/tasks/oil")
+		if task.StartAt == nil || task.Deadline == nil || task.Deadline.Sub(*task.StartAt) != 48*time.Hour || task.StartAt.UTC().Day() != 15 {
+			t.Errorf("got start %v and deadline %v, want January 15 and 17", task.StartAt, task.Deadline)
+		}
+
+		response = serveWithToken(server, alice, http.MethodPut, "/projects/garage/tasks/oil/snooze", `{"for": "1d"}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		serveWithToken(server, alice, http.MethodPut, "/projects/garage/tasks/buy broom/complete", "")
+		response = serveWithToken(server, alice, http.MethodPut, "/projects/garage/tasks/buy broom/snooze", `{"for": "1d"}`)
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+	})
+}
+
+// Tests for snoozes that are rejected
+func TestSnoozeValidation(t *testing.T) {
+	server, _ := setupTaskTests()
+
+	t.Run("Snoozes are validated", func(t *testing.T) {
+		for _, body := range []string{`{}`, `{"for": "-2h"}`, `{"for": "soon"}`, `{"until": "2000-01-01T00:00:00Z"}`, `{"for": "1d", "until": "2099-01-01T00:00:00Z"}`} {
+			request := newAuthenticatedRequest(http.MethodPut, "/projects/homework/tasks/math/snooze", bytes.NewBufferString(body))
+			response := httptest.NewRecorder()
+
+			server.Router.ServeHTTP(response, request)
+
+			assertResponseStatus(t, response.Code, http.StatusBadRequest)
+		}
+	})
+}
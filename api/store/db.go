@@ -1,39 +1,153 @@
 package store
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
+	"reflect"
+	"sort"
+	"time"
 
+	"github.com/mattn/go-sqlite3"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	model "github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/rank"
 )
 
 // TodoStore interface for testing
 // Tests use own implementation with
 // StubTodoStore instead of a real database
 type TodoStore interface {
-	GetProject(name string) model.Project
-	PostProject(name string) error
-	GetAllProjects() []model.Project
-	DeleteProject(name string) error
+	GetProject(workspaceID uint, name string) model.Project
+	PostProject(project model.Project) error
+	GetAllProjects(workspaceID, userID uint) []model.Project
+	GetAdjacentProjectPosition(project model.Project, after bool) string
+	DeleteProject(project model.Project) error
+	GetTrash(filter model.TrashFilter) []model.TrashItem
+	GetTrashedProject(id uint) model.Project
+	RestoreProject(project model.Project) error
+	PurgeProject(project model.Project) error
 	UpdateProject(project model.Project) error
+	GetRevisions(resourceType string, resourceID uint) []model.Revision
+	GetRevision(resourceType string, resourceID uint, number int) model.Revision
+	GetProjectByID(id uint) model.Project
 
-	GetTask(projectName, taskName string) model.Task
+	GetTask(project model.Project, taskName string) model.Task
+	GetTaskByID(id uint) model.Task
+	GetSubtasks(task model.Task) []model.Task
+	GetBlockers(task model.Task) []model.Task
+	GetBlockedTasks(task model.Task) []model.Task
+	GetBlockerIDs(taskID uint) []uint
+	GetTaskDependency(taskID, blockerID uint) model.TaskDependency
+	PostTaskDependency(dependency model.TaskDependency) error
+	DeleteTaskDependency(dependency model.TaskDependency) error
 	PostTask(task model.Task) error
-	GetAllProjectTasks(project model.Project) []model.Task
+	GetAllProjectTasks(project model.Project, filter model.TaskFilter) []model.Task
+	CountEstimatedTasks(project model.Project) int64
 	DeleteTask(task model.Task) error
+	GetTrashedTask(id uint) model.Task
+	GetTrashedSubtasks(task model.Task) []model.Task
+	RestoreTasks(tasks []model.Task) error
+	PurgeTasks(tasks []model.Task) error
 	UpdateTask(task model.Task) error
+	RepeatTask(completed model.Task, next model.Task) (model.Task, error)
+	GetAdjacentTaskPosition(task model.Task, after bool) string
+	MoveTasks(tasks []model.Task, project model.Project) error
+
+	GetUser(name string) model.User
+	GetUserByID(id uint) model.User
+	GetUserBySubject(subject string) model.User
+	PostUser(user model.User) error
+	PostToken(token model.Token) error
+	DeleteToken(hash string) error
+	GetUserByToken(hash string) model.User
+
+	GetMembership(projectID, userID uint) model.Membership
+	GetProjectMembers(projectID uint) []model.Membership
+	PostMembership(membership model.Membership) error
+	UpdateMembership(membership model.Membership) error
+	DeleteMembership(membership model.Membership) error
+
+	GetAPIKey(hash string) model.APIKey
+	GetAPIKeyByID(id uint) model.APIKey
+	GetAllAPIKeys(userID uint) []model.APIKey
+	PostAPIKey(key model.APIKey) error
+	UpdateAPIKey(key model.APIKey) error
+	MarkAPIKeyUsed(key model.APIKey) error
+
+	PostAuditEntry(entry model.AuditEntry) error
+	GetAuditEntries(filter model.AuditFilter) []model.AuditEntry
+
+	GetWorkspace(name string) model.Workspace
+	GetAllWorkspaces(userID uint) []model.Workspace
+	PostWorkspace(workspace model.Workspace) error
+	GetWorkspaceMember(workspaceID, userID uint) model.WorkspaceMember
+	GetWorkspaceMembers(workspaceID uint) []model.WorkspaceMember
+	PostWorkspaceMember(member model.WorkspaceMember) error
+	UpdateWorkspaceMember(member model.WorkspaceMember) error
+	DeleteWorkspaceMember(member model.WorkspaceMember) error
+
+	GetTag(workspaceID uint, name string) model.Tag
+	GetAllTags(workspaceID uint) []model.Tag
+	PostTag(tag model.Tag) error
+	UpdateTag(tag model.Tag) error
+	DeleteTag(tag model.Tag) error
+	AddTaskTag(task model.Task, tag model.Tag) error
+	DeleteTaskTag(task model.Task, tag model.Tag) error
+
+	AddTaskAssignee(task model.Task, user model.User) error
+	DeleteTaskAssignee(task model.Task, user model.User) error
+	GetAssignedTasks(userID uint) []model.Task
+
+	GetComment(id uint) model.Comment
+	GetComments(task model.Task, filter model.CommentFilter) []model.Comment
+	GetCommentReplies(task model.Task) []model.Comment
+	PostComment(comment model.Comment) (model.Comment, error)
+	UpdateComment(comment model.Comment) error
+	DeleteComment(comment model.Comment) ([]model.Comment, error)
+
+	GetAttachment(id uint) model.Attachment
+	GetAttachments(task model.Task) []model.Attachment
+	GetProjectAttachments(project model.Project) []model.Attachment
+	GetProjectAttachmentSize(project model.Project) int64
+	CountAttachments(hash string) int64
+	PostAttachment(attachment model.Attachment) (model.Attachment, error)
+	DeleteAttachment(attachment model.Attachment) error
+
+	GetChecklistItem(id uint) model.ChecklistItem
+	GetChecklist(task model.Task) []model.ChecklistItem
+	PostChecklistItem(item model.ChecklistItem) (model.ChecklistItem, error)
+	UpdateChecklistItem(item model.ChecklistItem) error
+	UpdateChecklist(items []model.ChecklistItem) error
+	DeleteChecklistItem(item model.ChecklistItem) error
+
+	GetTimeEntries(filter model.TimeEntryFilter) []model.TimeEntry
+	PostTimeEntry(entry model.TimeEntry) (model.TimeEntry, error)
+	UpdateTimeEntry(entry model.TimeEntry) error
+}
+
+// Sorts tasks from urgent to none
+const priorityOrder = "CASE Priority WHEN 'urgent' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END DESC"
+
+// Sorts tasks by the next deadline, tasks without deadline come last
+const deadlineOrder = "Deadline IS NULL, Deadline"
+
+// Sorts preloaded tags by name
+func orderByName(db *gorm.DB) *gorm.DB {
+	return db.Order("Name")
 }
 
 type Database struct {
 	DB *gorm.DB
 }
 
-// Gets project by name
-func (d *Database) GetProject(name string) model.Project {
+// Gets project by name in a workspace
+func (d *Database) GetProject(workspaceID uint, name string) model.Project {
 	project := model.Project{}
-	err := d.DB.Find(&project, "Name = ?", name).Error
+	err := d.DB.Find(&project, "Workspace_ID = ? AND Name = ?", workspaceID, name).Error
 
 	if err != nil {
 		return model.Project{}
@@ -42,41 +156,247 @@ func (d *Database) GetProject(name string) model.Project {
 	return project
 }
 
-// Creates a new project
-func (d *Database) PostProject(name string) error {
-	project := model.Project{}
-	project.Name = name
+// Creates a new project and makes its creator the owner
+func (d *Database) PostProject(project model.Project) error {
 	project.Archived = false
 
-	err := d.DB.Create(&project).Error
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		// New projects are added at the end of the workspace
+		if project.Position == "" {
+			project.Position = rank.After(lastPosition(tx.Model(&model.Project{}).Where("Workspace_ID = ?", project.WorkspaceID)))
+		}
+
+		if err := tx.Create(&project).Error; err != nil {
+			return err
+		}
+
+		owner := model.Membership{ProjectID: project.ID, UserID: project.UserID, Role: model.RoleOwner}
+		return tx.Create(&owner).Error
+	})
 
 	return err
 }
 
-// Return an array of all projects
-func (d *Database) GetAllProjects() []model.Project {
+// Return an array of all projects in a workspace the user is a member of
+func (d *Database) GetAllProjects(workspaceID, userID uint) []model.Project {
 	projects := []model.Project{}
 
-	d.DB.Find(&projects)
+	d.DB.Joins("JOIN memberships ON memberships.project_id = projects.id").
+		Where("projects.workspace_id = ? AND memberships.user_id = ? AND memberships.deleted_at IS NULL", workspaceID, userID).
+		Order("projects.position").Order("projects.id").Find(&projects)
 
 	return projects
 }
 
-// Delete a project
-func (d *Database) DeleteProject(name string) error {
+// Moves a project to the trash and stops the timers of its tasks.
+// Its tasks, memberships and attachments are kept for a restore
+func (d *Database) DeleteProject(project model.Project) error {
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := stopTimers(tx, tx.Model(&model.Task{}).Select("ID").Where("Project_ID = ?", project.ID)); err != nil {
+			return err
+		}
+		return tx.Delete(&model.Project{}, project.ID).Error
+	})
+	return err
+}
+
+// Returns the deleted projects and the deleted tasks of projects outside the trash matching the filter, newest first
+func (d *Database) GetTrash(filter model.TrashFilter) []model.TrashItem {
+	projects := []model.Project{}
+	tasks := []model.Task{}
+
+	projectQuery := d.DB.Unscoped().Where("Deleted_At IS NOT NULL")
+	taskQuery := d.DB.Unscoped().Where("Deleted_At IS NOT NULL").
+		Where("Project_ID IN (?)", d.DB.Model(&model.Project{}).Select("ID")).
+		Where("(Parent_ID IS NULL OR Parent_ID IN (?))", d.DB.Model(&model.Task{}).Select("ID"))
+
+	if filter.OwnerID != 0 {
+		owned := d.DB.Model(&model.Membership{}).Select("Project_ID").Where("User_ID = ? AND Role = ?", filter.OwnerID, model.RoleOwner)
+		projectQuery = projectQuery.Where("ID IN (?)", owned)
+		taskQuery = taskQuery.Where("Project_ID IN (?)", owned)
+	}
+	// sqlite compares timestamps as strings, so they need the same time zone as the stored ones
+	if !filter.Before.IsZero() {
+		projectQuery = projectQuery.Where("Deleted_At < ?", filter.Before.Local())
+		taskQuery = taskQuery.Where("Deleted_At < ?", filter.Before.Local())
+	}
+
+	projectQuery.Find(&projects)
+	taskQuery.Find(&tasks)
+
+	// Loads the projects of the tasks at once instead of one query per task
+	projectIDs := []uint{}
+	for _, task := range tasks {
+		projectIDs = append(projectIDs, task.ProjectID)
+	}
+	taskProjects := []model.Project{}
+	if len(projectIDs) > 0 {
+		d.DB.Where("ID IN ?", projectIDs).Find(&taskProjects)
+	}
+	projectNames := map[uint]string{}
+	for _, project := range taskProjects {
+		projectNames[project.ID] = project.Name
+	}
+
+	items := []model.TrashItem{}
+	for _, project := range projects {
+		items = append(items, model.TrashItem{ResourceType: model.ResourceProject, ID: project.ID, Name: project.Name, ProjectID: project.ID, DeletedAt: project.DeletedAt.Time})
+	}
+	for _, task := range tasks {
+		items = append(items, model.TrashItem{ResourceType: model.ResourceTask, ID: task.ID, Name: task.Name, ProjectID: task.ProjectID, DeletedAt: task.DeletedAt.Time,
+			Project: projectNames[task.ProjectID]})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items
+}
+
+// Gets a deleted project by ID
+func (d *Database) GetTrashedProject(id uint) model.Project {
 	project := model.Project{}
+	err := d.DB.Unscoped().Where("Deleted_At IS NOT NULL").Find(&project, id).Error
+
+	if err != nil {
+		return model.Project{}
+	}
+
+	return project
+}
+
+// Takes a project out of the trash. Its tasks stayed in place while the project was deleted,
+// so only the deletion of the project is undone
+func (d *Database) RestoreProject(project model.Project) error {
+	err := d.DB.Unscoped().Model(&model.Project{}).Where("ID = ?", project.ID).Update("Deleted_At", nil).Error
+	return err
+}
 
-	// Unscoped to delete project permanently
-	err := d.DB.Unscoped().Where("Name = ?", name).Delete(&project).Error
+// Deletes a project permanently with all of its tasks, members and revisions
+func (d *Database) PurgeProject(project model.Project) error {
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Unscoped().Model(&model.Task{}).Where("Project_ID = ?", project.ID).Pluck("ID", &ids).Error; err != nil {
+			return err
+		}
+		if err := purgeTasks(tx, ids); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("Project_ID = ?", project.ID).Delete(&model.Membership{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("Project_ID = ?", project.ID).Delete(&model.Attachment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("Resource_Type = ? AND Resource_ID = ?", model.ResourceProject, project.ID).Delete(&model.Revision{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&model.Project{}, project.ID).Error
+	})
 	return err
 }
 
 // Update a project
 func (d *Database) UpdateProject(project model.Project) error {
-	err := d.DB.Save(&project).Error
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordRevision(tx, model.ResourceProject, project.ID, &model.Project{}, &project); err != nil {
+			return err
+		}
+		return tx.Save(&project).Error
+	})
 	return err
 }
 
+// Returns the revisions of a task or project, newest first
+func (d *Database) GetRevisions(resourceType string, resourceID uint) []model.Revision {
+	revisions := []model.Revision{}
+	d.DB.Where("Resource_Type = ? AND Resource_ID = ?", resourceType, resourceID).Order("Number DESC").Find(&revisions)
+
+	return revisions
+}
+
+// Returns a revision of a task or project by its number
+func (d *Database) GetRevision(resourceType string, resourceID uint, number int) model.Revision {
+	revision := model.Revision{}
+	err := d.DB.Find(&revision, "Resource_Type = ? AND Resource_ID = ? AND Number = ?", resourceType, resourceID, number).Error
+
+	if err != nil {
+		return model.Revision{}
+	}
+
+	return revision
+}
+
+// Stores the row of the updated value as the next revision of the resource with the columns the update
+// changes. Updates that change nothing are recorded too, with no changed columns. stored receives the row
+// and has the type of updated
+func recordRevision(tx *gorm.DB, resourceType string, resourceID uint, stored, updated interface{}) error {
+	result := tx.Find(stored, resourceID)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	changed, err := changedColumns(tx, stored, updated)
+	if err != nil {
+		return err
+	}
+
+	revision := model.Revision{ResourceType: resourceType, ResourceID: resourceID}
+	if revision.Changed, err = json.Marshal(changed); err != nil {
+		return err
+	}
+	if revision.Snapshot, err = json.Marshal(stored); err != nil {
+		return err
+	}
+
+	var last []int
+	tx.Model(&model.Revision{}).Where("Resource_Type = ? AND Resource_ID = ?", resourceType, resourceID).Pluck("COALESCE(MAX(Number), 0)", &last)
+	if len(last) > 0 {
+		revision.Number = last[0]
+	}
+	revision.Number++
+
+	return tx.Create(&revision).Error
+}
+
+// Returns the names of the columns that differ between two values of a model.
+// Timestamps kept by gorm are left out, times are equal at the same instant
+func changedColumns(tx *gorm.DB, before, after interface{}) ([]string, error) {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(before); err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	for _, field := range stmt.Schema.Fields {
+		switch field.DBName {
+		case "", "created_at", "updated_at", "deleted_at":
+			continue
+		}
+
+		a := field.ReflectValueOf(reflect.Indirect(reflect.ValueOf(before))).Interface()
+		b := field.ReflectValueOf(reflect.Indirect(reflect.ValueOf(after))).Interface()
+		if !sameValue(a, b) {
+			changed = append(changed, field.DBName)
+		}
+	}
+	return changed, nil
+}
+
+func sameValue(a, b interface{}) bool {
+	switch ta := a.(type) {
+	case time.Time:
+		return ta.Equal(b.(time.Time))
+	case *time.Time:
+		tb := b.(*time.Time)
+		if ta == nil || tb == nil {
+			return ta == tb
+		}
+		return ta.Equal(*tb)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
 // Get project by ID
 func (d *Database) GetProjectByID(id uint) model.Project {
 	project := model.Project{}
@@ -89,52 +409,662 @@ func (d *Database) GetProjectByID(id uint) model.Project {
 	return project
 }
 
-// Get a task
-func (d *Database) GetTask(projectName, taskName string) model.Task {
-	project := model.Project{}
-	err := d.DB.Find(&project, "Name = ?", projectName).Error
+// Get a task of a project
+func (d *Database) GetTask(project model.Project, taskName string) model.Task {
+	task := model.Task{}
+	err := d.DB.Preload("Tags", orderByName).Preload("Assignees", orderByName).Find(&task, "Name = ? AND Project_ID = ?", taskName, project.ID).Error
 
 	if err != nil {
 		return model.Task{}
 	}
 
+	d.countProgress([]*model.Task{&task})
+	return task
+}
+
+// Get task by ID
+func (d *Database) GetTaskByID(id uint) model.Task {
 	task := model.Task{}
-	err = d.DB.Find(&task, "Name = ? AND Project_ID = ?", taskName, project.ID).Error
+	err := d.DB.Preload("Tags", orderByName).Preload("Assignees", orderByName).Find(&task, id).Error
 
 	if err != nil {
 		return model.Task{}
 	}
 
+	d.countProgress([]*model.Task{&task})
 	return task
 }
 
-// Create a Task
+// Returns the direct subtasks of a task
+func (d *Database) GetSubtasks(task model.Task) []model.Task {
+	tasks := []model.Task{}
+
+	d.DB.Preload("Tags", orderByName).Preload("Assignees", orderByName).Order("Position").Order("ID").Find(&tasks, "Parent_ID = ?", task.ID)
+
+	d.countProgress(taskPointers(tasks))
+	return tasks
+}
+
+// Sets the subtask and checklist progress of the tasks
+func (d *Database) countProgress(tasks []*model.Task) {
+	d.countSubtasks(tasks)
+	d.countChecklists(tasks)
+}
+
+// Sets the subtask progress of the tasks
+func (d *Database) countSubtasks(tasks []*model.Task) {
+	ids := []uint{}
+	byID := map[uint]*model.Task{}
+	for _, task := range tasks {
+		if task.ID != 0 {
+			ids = append(ids, task.ID)
+			byID[task.ID] = task
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	var counts []struct {
+		ParentID uint
+		Total    int
+		Done     int
+	}
+	d.DB.Model(&model.Task{}).Select("Parent_ID, COUNT(*) AS Total, SUM(CASE WHEN Done THEN 1 ELSE 0 END) AS Done").
+		Where("Parent_ID IN ?", ids).Group("Parent_ID").Scan(&counts)
+
+	for _, count := range counts {
+		byID[count.ParentID].Subtasks = model.SubtaskProgress{Total: count.Total, Done: count.Done}
+	}
+}
+
+// Sets the number of checked and all checklist items of the tasks
+func (d *Database) countChecklists(tasks []*model.Task) {
+	ids := []uint{}
+	byID := map[uint]*model.Task{}
+	for _, task := range tasks {
+		if task.ID != 0 {
+			ids = append(ids, task.ID)
+			byID[task.ID] = task
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	var counts []struct {
+		TaskID uint
+		Total  int
+		Done   int
+	}
+	d.DB.Model(&model.ChecklistItem{}).Select("Task_ID, COUNT(*) AS Total, SUM(CASE WHEN Checked THEN 1 ELSE 0 END) AS Done").
+		Where("Task_ID IN ?", ids).Group("Task_ID").Scan(&counts)
+
+	for _, count := range counts {
+		byID[count.TaskID].ChecklistTotal = count.Total
+		byID[count.TaskID].ChecklistDone = count.Done
+	}
+}
+
+// Returns pointers to the tasks of the slice
+func taskPointers(tasks []model.Task) []*model.Task {
+	pointers := make([]*model.Task, len(tasks))
+	for i := range tasks {
+		pointers[i] = &tasks[i]
+	}
+	return pointers
+}
+
+// Create a Task at the end of its project, tags are attached separately
 func (d *Database) PostTask(task model.Task) error {
-	err := d.DB.Create(&task).Error
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if task.Position == "" {
+			task.Position = rank.After(lastPosition(tx.Model(&model.Task{}).Where("Project_ID = ?", task.ProjectID)))
+		}
+		return tx.Omit(clause.Associations).Create(&task).Error
+	})
 	return err
 }
 
 // Returns an array of all tasks belonging to a project
-func (d *Database) GetAllProjectTasks(project model.Project) []model.Task {
+func (d *Database) GetAllProjectTasks(project model.Project, filter model.TaskFilter) []model.Task {
 	tasks := []model.Task{}
+	query := d.DB.Preload("Tags", orderByName).Preload("Assignees", orderByName)
 
-	d.DB.Find(&tasks, "Project_ID = ?", project.ID)
+	if len(filter.Tags) > 0 {
+		tagged := d.DB.Table("task_tags").Select("task_tags.task_id").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Where("tags.name IN ?", filter.Tags).Group("task_tags.task_id")
+
+		if filter.Match == model.MatchAll {
+			tagged = tagged.Having("COUNT(DISTINCT tags.id) = ?", len(filter.Tags))
+		}
+		query = query.Where("ID IN (?)", tagged)
+	}
+
+	if len(filter.Assignees) > 0 || filter.Unassigned {
+		assigned := d.DB.Table("task_assignees").Select("task_assignees.task_id")
+		named := d.DB.Table("task_assignees").Select("task_assignees.task_id").
+			Joins("JOIN users ON users.id = task_assignees.user_id").Where("users.name IN ?", filter.Assignees)
+
+		switch {
+		case len(filter.Assignees) > 0 && filter.Unassigned:
+			query = query.Where("(ID IN (?) OR ID NOT IN (?))", named, assigned)
+		case filter.Unassigned:
+			query = query.Where("ID NOT IN (?)", assigned)
+		default:
+			query = query.Where("ID IN (?)", named)
+		}
+	}
+
+	switch filter.Sort {
+	case model.SortPriority:
+		query = query.Order(priorityOrder).Order(deadlineOrder)
+	case model.SortDeadline:
+		query = query.Order(deadlineOrder)
+	}
+
+	query.Order("Position").Order("ID").Find(&tasks, "Project_ID = ?", project.ID)
+
+	d.countProgress(taskPointers(tasks))
 
 	return tasks
 }
 
-// Deletes a Task
+// Counts the tasks of the project with an estimate or remaining effort, tasks in the trash included
+func (d *Database) CountEstimatedTasks(project model.Project) int64 {
+	var count int64
+
+	d.DB.Unscoped().Model(&model.Task{}).Where("Project_ID = ? AND (Estimate IS NOT NULL OR Remaining IS NOT NULL)", project.ID).Count(&count)
+
+	return count
+}
+
+// Moves a task to the trash and stops its timer. Its subtasks, dependencies, tags, assignees,
+// comments, attachments, checklist and time entries are kept for a restore
 func (d *Database) DeleteTask(task model.Task) error {
-	err := d.DB.Unscoped().Where("Name = ? AND Project_ID = ?", task.Name, task.ProjectID).Delete(&task).Error
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := stopTimers(tx, []uint{task.ID}); err != nil {
+			return err
+		}
+		return tx.Delete(&model.Task{}, task.ID).Error
+	})
+	return err
+}
+
+// Ends the running timers of the tasks, ids is a list or a query of task IDs
+func stopTimers(tx *gorm.DB, ids interface{}) error {
+	err := tx.Model(&model.TimeEntry{}).Where("Task_ID IN (?) AND Ended_At IS NULL", ids).Update("Ended_At", time.Now()).Error
+	return err
+}
+
+// Gets a deleted task by ID
+func (d *Database) GetTrashedTask(id uint) model.Task {
+	task := model.Task{}
+	err := d.DB.Unscoped().Where("Deleted_At IS NOT NULL").Find(&task, id).Error
+
+	if err != nil {
+		return model.Task{}
+	}
+
+	return task
+}
+
+// Returns the deleted subtasks of a task on all levels below it, a level at a time
+func (d *Database) GetTrashedSubtasks(task model.Task) []model.Task {
+	subtasks := []model.Task{}
+
+	parents := []uint{task.ID}
+	for len(parents) > 0 {
+		level := []model.Task{}
+		d.DB.Unscoped().Where("Deleted_At IS NOT NULL AND Parent_ID IN ?", parents).Order("ID").Find(&level)
+
+		parents = []uint{}
+		for _, subtask := range level {
+			parents = append(parents, subtask.ID)
+		}
+		subtasks = append(subtasks, level...)
+	}
+
+	return subtasks
+}
+
+// Takes the tasks out of the trash
+func (d *Database) RestoreTasks(tasks []model.Task) error {
+	err := d.DB.Unscoped().Model(&model.Task{}).Where("ID IN ?", taskIDs(tasks)).Update("Deleted_At", nil).Error
+	return err
+}
+
+// Deletes the tasks permanently with everything belonging to them
+func (d *Database) PurgeTasks(tasks []model.Task) error {
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		return purgeTasks(tx, taskIDs(tasks))
+	})
+	return err
+}
+
+func purgeTasks(tx *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := tx.Where("Task_ID IN ? OR Blocker_ID IN ?", ids, ids).Delete(&model.TaskDependency{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("Task_ID IN ?", ids).Delete(&model.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("Task_ID IN ?", ids).Delete(&model.Attachment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("Task_ID IN ?", ids).Delete(&model.ChecklistItem{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("Task_ID IN ?", ids).Delete(&model.TimeEntry{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("Resource_Type = ? AND Resource_ID IN ?", model.ResourceTask, ids).Delete(&model.Revision{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM task_tags WHERE task_id IN ?", ids).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM task_assignees WHERE task_id IN ?", ids).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("ID IN ?", ids).Delete(&model.Task{}).Error
+}
+
+func taskIDs(tasks []model.Task) []uint {
+	ids := []uint{}
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+// Returns the tasks blocking a task
+func (d *Database) GetBlockers(task model.Task) []model.Task {
+	tasks := []model.Task{}
+
+	d.DB.Where("ID IN (?)", d.DB.Model(&model.TaskDependency{}).Select("Blocker_ID").Where("Task_ID = ?", task.ID)).
+		Order("ID").Find(&tasks)
+
+	return tasks
+}
+
+// Returns the tasks blocked by a task
+func (d *Database) GetBlockedTasks(task model.Task) []model.Task {
+	tasks := []model.Task{}
+
+	d.DB.Where("ID IN (?)", d.DB.Model(&model.TaskDependency{}).Select("Task_ID").Where("Blocker_ID = ?", task.ID)).
+		Order("ID").Find(&tasks)
+
+	return tasks
+}
+
+// Returns the IDs of the tasks blocking a task, including tasks in the trash
+func (d *Database) GetBlockerIDs(taskID uint) []uint {
+	ids := []uint{}
+
+	d.DB.Model(&model.TaskDependency{}).Where("Task_ID = ?", taskID).Order("Blocker_ID").Pluck("Blocker_ID", &ids)
+
+	return ids
+}
+
+// Gets the dependency of a task on a blocker
+func (d *Database) GetTaskDependency(taskID, blockerID uint) model.TaskDependency {
+	dependency := model.TaskDependency{}
+	err := d.DB.Find(&dependency, "Task_ID = ? AND Blocker_ID = ?", taskID, blockerID).Error
+
+	if err != nil {
+		return model.TaskDependency{}
+	}
+
+	return dependency
+}
+
+// Adds a blocker to a task
+func (d *Database) PostTaskDependency(dependency model.TaskDependency) error {
+	err := d.DB.Create(&dependency).Error
 	return err
 }
 
-// Updates a task
+// Removes a blocker from a task
+func (d *Database) DeleteTaskDependency(dependency model.TaskDependency) error {
+	err := d.DB.Delete(&dependency).Error
+	return err
+}
+
+// Updates a task without its tags
 func (d *Database) UpdateTask(task model.Task) error {
-	err := d.DB.Save(&task).Error
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordRevision(tx, model.ResourceTask, task.ID, &model.Task{}, &task); err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Save(&task).Error
+	})
+	return err
+}
+
+// Stores the completed occurrence of a repeating task and creates its next occurrence at the end of the project.
+// The next occurrence gets the tags, assignees and subtasks of the completed one and a copy of its checklist
+// with all items unchecked, comments, attachments and time entries stay with the completed occurrence
+func (d *Database) RepeatTask(completed model.Task, next model.Task) (model.Task, error) {
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := recordRevision(tx, model.ResourceTask, completed.ID, &model.Task{}, &completed); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(&completed).Error; err != nil {
+			return err
+		}
+
+		next.Position = rank.After(lastPosition(tx.Model(&model.Task{}).Where("Project_ID = ?", next.ProjectID)))
+		if err := tx.Omit(clause.Associations).Create(&next).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) SELECT ?, tag_id FROM task_tags WHERE task_id = ?", next.ID, completed.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("INSERT INTO task_assignees (task_id, user_id) SELECT ?, user_id FROM task_assignees WHERE task_id = ?", next.ID, completed.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Task{}).Where("Parent_ID = ?", completed.ID).Update("Parent_ID", next.ID).Error; err != nil {
+			return err
+		}
+
+		items := []model.ChecklistItem{}
+		tx.Where("Task_ID = ?", completed.ID).Order("Position").Order("ID").Find(&items)
+
+		for _, item := range items {
+			copied := model.ChecklistItem{TaskID: next.ID, Text: item.Text, Position: item.Position}
+			if err := tx.Create(&copied).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return next, err
+}
+
+// Moves the tasks to the end of the project under their current names. Attachments and time entries
+// move with them, tags of other workspaces and assignees who are no members of the project are removed
+func (d *Database) MoveTasks(tasks []model.Task, project model.Project) error {
+	ids := taskIDs(tasks)
+
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		for _, task := range tasks {
+			position := rank.After(lastPosition(tx.Model(&model.Task{}).Where("Project_ID = ?", project.ID)))
+
+			err := tx.Model(&model.Task{}).Where("ID = ?", task.ID).
+				Updates(map[string]interface{}{"name": task.Name, "project_id": project.ID, "position": position}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&model.Attachment{}).Where("Task_ID IN ?", ids).Update("project_id", project.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.TimeEntry{}).Where("Task_ID IN ?", ids).Update("project_id", project.ID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Exec("DELETE FROM task_tags WHERE task_id IN ? AND tag_id IN (SELECT id FROM tags WHERE workspace_id <> ?)", ids, project.WorkspaceID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM task_assignees WHERE task_id IN ? AND user_id NOT IN (SELECT user_id FROM memberships WHERE project_id = ? AND deleted_at IS NULL)", ids, project.ID).Error
+	})
+	return err
+}
+
+// Returns the position of the task right before or after the task in its project or an empty string at the ends
+func (d *Database) GetAdjacentTaskPosition(task model.Task, after bool) string {
+	return adjacentPosition(d.DB.Model(&model.Task{}).Where("Project_ID = ?", task.ProjectID), task.Position, after)
+}
+
+// Returns the position of the project right before or after the project in its workspace or an empty string at the ends
+func (d *Database) GetAdjacentProjectPosition(project model.Project, after bool) string {
+	return adjacentPosition(d.DB.Model(&model.Project{}).Where("Workspace_ID = ?", project.WorkspaceID), project.Position, after)
+}
+
+// Returns the next smaller or larger position than the position in the query.
+// Trashed rows keep their position, so they are included to stay unique on restore
+func adjacentPosition(query *gorm.DB, position string, after bool) string {
+	var positions []string
+
+	query = query.Unscoped()
+	if after {
+		query.Where("Position > ?", position).Order("Position").Limit(1).Pluck("Position", &positions)
+	} else {
+		query.Where("Position < ?", position).Order("Position DESC").Limit(1).Pluck("Position", &positions)
+	}
+
+	if len(positions) == 0 {
+		return ""
+	}
+	return positions[0]
+}
+
+// Returns the largest position in the query or an empty string if it has no rows.
+// Trashed rows are included like in adjacentPosition
+func lastPosition(query *gorm.DB) string {
+	var positions []string
+
+	query.Unscoped().Order("Position DESC").Limit(1).Pluck("Position", &positions)
+
+	if len(positions) == 0 {
+		return ""
+	}
+	return positions[0]
+}
+
+// Gets user by name
+func (d *Database) GetUser(name string) model.User {
+	user := model.User{}
+	err := d.DB.Find(&user, "Name = ?", name).Error
+
+	if err != nil {
+		return model.User{}
+	}
+
+	return user
+}
+
+// Gets user by ID
+func (d *Database) GetUserByID(id uint) model.User {
+	user := model.User{}
+	err := d.DB.Find(&user, id).Error
+
+	if err != nil {
+		return model.User{}
+	}
+
+	return user
+}
+
+// Gets the user linked to the sub claim of an identity provider
+func (d *Database) GetUserBySubject(subject string) model.User {
+	user := model.User{}
+	err := d.DB.Find(&user, "Subject = ?", subject).Error
+
+	if err != nil {
+		return model.User{}
+	}
+
+	return user
+}
+
+// Creates a new user
+func (d *Database) PostUser(user model.User) error {
+	err := d.DB.Create(&user).Error
+	return err
+}
+
+// Stores a login token
+func (d *Database) PostToken(token model.Token) error {
+	err := d.DB.Create(&token).Error
+	return err
+}
+
+// Deletes a login token
+func (d *Database) DeleteToken(hash string) error {
+	err := d.DB.Unscoped().Where("Hash = ?", hash).Delete(&model.Token{}).Error
 	return err
 }
 
+// Gets the user a token was issued to. Returns an
+// empty user if the token does not exist or is expired
+func (d *Database) GetUserByToken(hash string) model.User {
+	token := model.Token{}
+	err := d.DB.Find(&token, "Hash = ? AND Expires_At > ?", hash, time.Now()).Error
+
+	if err != nil || token.ID == 0 {
+		return model.User{}
+	}
+
+	user := model.User{}
+	err = d.DB.Find(&user, token.UserID).Error
+
+	if err != nil {
+		return model.User{}
+	}
+
+	return user
+}
+
+// Gets the membership of a user in a project
+func (d *Database) GetMembership(projectID, userID uint) model.Membership {
+	membership := model.Membership{}
+	err := d.DB.Preload("User").Find(&membership, "Project_ID = ? AND User_ID = ?", projectID, userID).Error
+
+	if err != nil {
+		return model.Membership{}
+	}
+
+	return membership
+}
+
+// Returns all members of a project
+func (d *Database) GetProjectMembers(projectID uint) []model.Membership {
+	members := []model.Membership{}
+
+	d.DB.Preload("User").Order("ID").Find(&members, "Project_ID = ?", projectID)
+
+	return members
+}
+
+// Adds a user to a project
+func (d *Database) PostMembership(membership model.Membership) error {
+	err := d.DB.Create(&membership).Error
+	return err
+}
+
+// Changes the role of a member
+func (d *Database) UpdateMembership(membership model.Membership) error {
+	err := d.DB.Model(&membership).Update("Role", membership.Role).Error
+	return err
+}
+
+// Removes a user from a project
+func (d *Database) DeleteMembership(membership model.Membership) error {
+	err := d.DB.Unscoped().Delete(&membership).Error
+	return err
+}
+
+// Gets an API key by the hash of the plaintext key
+func (d *Database) GetAPIKey(hash string) model.APIKey {
+	key := model.APIKey{}
+	err := d.DB.Find(&key, "Hash = ?", hash).Error
+
+	if err != nil {
+		return model.APIKey{}
+	}
+
+	return key
+}
+
+// Gets an API key by ID
+func (d *Database) GetAPIKeyByID(id uint) model.APIKey {
+	key := model.APIKey{}
+	err := d.DB.Find(&key, id).Error
+
+	if err != nil {
+		return model.APIKey{}
+	}
+
+	return key
+}
+
+// Returns all API keys of a user
+func (d *Database) GetAllAPIKeys(userID uint) []model.APIKey {
+	keys := []model.APIKey{}
+
+	d.DB.Order("ID").Find(&keys, "User_ID = ?", userID)
+
+	return keys
+}
+
+// Stores a new API key
+func (d *Database) PostAPIKey(key model.APIKey) error {
+	err := d.DB.Create(&key).Error
+	return err
+}
+
+// Updates an API key
+func (d *Database) UpdateAPIKey(key model.APIKey) error {
+	err := d.DB.Save(&key).Error
+	return err
+}
+
+// Sets the last used timestamp of an API key to now
+func (d *Database) MarkAPIKeyUsed(key model.APIKey) error {
+	err := d.DB.Model(&key).UpdateColumn("last_used_at", time.Now()).Error
+	return err
+}
+
+// Appends an entry to the audit log
+func (d *Database) PostAuditEntry(entry model.AuditEntry) error {
+	err := d.DB.Create(&entry).Error
+	return err
+}
+
+// Returns audit log entries matching the filter, newest first
+func (d *Database) GetAuditEntries(filter model.AuditFilter) []model.AuditEntry {
+	entries := []model.AuditEntry{}
+	query := d.DB.Model(&model.AuditEntry{})
+
+	if filter.VisibleTo != 0 {
+		query = query.Where("(Actor_ID = ? OR Project_ID IN (?))", filter.VisibleTo,
+			d.DB.Model(&model.Membership{}).Select("Project_ID").Where("User_ID = ?", filter.VisibleTo))
+	}
+	if filter.Actor != "" {
+		query = query.Where("Actor = ?", filter.Actor)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("Resource_Type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != 0 {
+		query = query.Where("Resource_ID = ?", filter.ResourceID)
+	}
+	// sqlite compares timestamps as strings, so they need the same time zone as the stored ones
+	if !filter.From.IsZero() {
+		query = query.Where("Created_At >= ?", filter.From.Local())
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("Created_At <= ?", filter.To.Local())
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	query.Order("ID DESC").Find(&entries)
+
+	return entries
+}
+
 // creates database struct and runs automigrate
 func NewDatabaseConnection(name string) *Database {
 	db, err := gorm.Open(sqlite.Open(name), &gorm.Config{})
@@ -147,3 +1077,408 @@ func NewDatabaseConnection(name string) *Database {
 
 	return &Database{DB: db}
 }
+
+// Gets workspace by name
+func (d *Database) GetWorkspace(name string) model.Workspace {
+	workspace := model.Workspace{}
+	err := d.DB.Find(&workspace, "Name = ?", name).Error
+
+	if err != nil {
+		return model.Workspace{}
+	}
+
+	return workspace
+}
+
+// Returns the default workspace and all workspaces the user is a member of
+func (d *Database) GetAllWorkspaces(userID uint) []model.Workspace {
+	workspaces := []model.Workspace{}
+
+	d.DB.Where("Name = ? OR ID IN (?)", model.DefaultWorkspaceName,
+		d.DB.Model(&model.WorkspaceMember{}).Select("Workspace_ID").Where("User_ID = ?", userID)).
+		Order("ID").Find(&workspaces)
+
+	return workspaces
+}
+
+// Creates a new workspace and makes its creator the owner
+func (d *Database) PostWorkspace(workspace model.Workspace) error {
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&workspace).Error; err != nil {
+			return err
+		}
+
+		owner := model.WorkspaceMember{WorkspaceID: workspace.ID, UserID: workspace.UserID, Role: model.RoleOwner}
+		return tx.Create(&owner).Error
+	})
+
+	return err
+}
+
+// Gets the membership of a user in a workspace
+func (d *Database) GetWorkspaceMember(workspaceID, userID uint) model.WorkspaceMember {
+	member := model.WorkspaceMember{}
+	err := d.DB.Preload("User").Find(&member, "Workspace_ID = ? AND User_ID = ?", workspaceID, userID).Error
+
+	if err != nil {
+		return model.WorkspaceMember{}
+	}
+
+	return member
+}
+
+// Returns all members of a workspace
+func (d *Database) GetWorkspaceMembers(workspaceID uint) []model.WorkspaceMember {
+	members := []model.WorkspaceMember{}
+
+	d.DB.Preload("User").Order("ID").Find(&members, "Workspace_ID = ?", workspaceID)
+
+	return members
+}
+
+// Adds a user to a workspace
+func (d *Database) PostWorkspaceMember(member model.WorkspaceMember) error {
+	err := d.DB.Create(&member).Error
+	return err
+}
+
+// Changes the role of a workspace member
+func (d *Database) UpdateWorkspaceMember(member model.WorkspaceMember) error {
+	err := d.DB.Model(&member).Update("Role", member.Role).Error
+	return err
+}
+
+// Removes a user from a workspace
+func (d *Database) DeleteWorkspaceMember(member model.WorkspaceMember) error {
+	err := d.DB.Unscoped().Delete(&member).Error
+	return err
+}
+
+// Gets tag by name in a workspace
+func (d *Database) GetTag(workspaceID uint, name string) model.Tag {
+	tag := model.Tag{}
+	err := d.DB.Find(&tag, "Workspace_ID = ? AND Name = ?", workspaceID, name).Error
+
+	if err != nil {
+		return model.Tag{}
+	}
+
+	return tag
+}
+
+// Returns all tags of a workspace
+func (d *Database) GetAllTags(workspaceID uint) []model.Tag {
+	tags := []model.Tag{}
+
+	d.DB.Order("Name").Find(&tags, "Workspace_ID = ?", workspaceID)
+
+	return tags
+}
+
+// Creates a tag
+func (d *Database) PostTag(tag model.Tag) error {
+	err := d.DB.Create(&tag).Error
+	return err
+}
+
+// Renames a tag
+func (d *Database) UpdateTag(tag model.Tag) error {
+	err := d.DB.Save(&tag).Error
+	return err
+}
+
+// Deletes a tag and detaches it from all tasks
+func (d *Database) DeleteTag(tag model.Tag) error {
+	// Unscoped to delete tag permanently
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&model.Tag{}, tag.ID).Error
+	})
+	return err
+}
+
+// Attaches a tag to a task
+func (d *Database) AddTaskTag(task model.Task, tag model.Tag) error {
+	err := d.DB.Model(&task).Omit("Tags.*").Association("Tags").Append(&tag)
+	return err
+}
+
+// Detaches a tag from a task
+func (d *Database) DeleteTaskTag(task model.Task, tag model.Tag) error {
+	err := d.DB.Model(&task).Association("Tags").Delete(&tag)
+	return err
+}
+
+// Assigns a user to a task
+func (d *Database) AddTaskAssignee(task model.Task, user model.User) error {
+	err := d.DB.Model(&task).Omit("Assignees.*").Association("Assignees").Append(&user)
+	return err
+}
+
+// Unassigns a user from a task
+func (d *Database) DeleteTaskAssignee(task model.Task, user model.User) error {
+	err := d.DB.Model(&task).Association("Assignees").Delete(&user)
+	return err
+}
+
+// Returns the open tasks assigned to the user in projects the user is a member of,
+// the next deadline first
+func (d *Database) GetAssignedTasks(userID uint) []model.Task {
+	tasks := []model.Task{}
+
+	d.DB.Preload("Tags", orderByName).Preload("Assignees", orderByName).
+		Where("ID IN (?)", d.DB.Table("task_assignees").Select("task_id").Where("user_id = ?", userID)).
+		Where("Project_ID IN (?)", d.DB.Model(&model.Membership{}).Select("Project_ID").Where("User_ID = ?", userID)).
+		Where("Project_ID IN (?)", d.DB.Model(&model.Project{}).Select("ID")).
+		Where("Done = ?", false).
+		Order(deadlineOrder).Order("ID").Find(&tasks)
+
+	d.countProgress(taskPointers(tasks))
+	return tasks
+}
+
+// Gets comment by ID
+func (d *Database) GetComment(id uint) model.Comment {
+	comment := model.Comment{}
+	err := d.DB.Preload("User").Find(&comment, id).Error
+
+	if err != nil {
+		return model.Comment{}
+	}
+
+	return comment
+}
+
+// Returns a page of the top-level comments of a task, oldest first
+func (d *Database) GetComments(task model.Task, filter model.CommentFilter) []model.Comment {
+	comments := []model.Comment{}
+	query := d.DB.Preload("User").Where("Task_ID = ? AND Parent_ID IS NULL", task.ID)
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	query.Order("ID").Find(&comments)
+
+	return comments
+}
+
+// Returns all replies to comments of a task, oldest first
+func (d *Database) GetCommentReplies(task model.Task) []model.Comment {
+	comments := []model.Comment{}
+
+	d.DB.Preload("User").Order("ID").Find(&comments, "Task_ID = ? AND Parent_ID IS NOT NULL", task.ID)
+
+	return comments
+}
+
+// Creates a comment and returns it with its ID
+func (d *Database) PostComment(comment model.Comment) (model.Comment, error) {
+	err := d.DB.Omit(clause.Associations).Create(&comment).Error
+	return comment, err
+}
+
+// Updates a comment
+func (d *Database) UpdateComment(comment model.Comment) error {
+	err := d.DB.Omit(clause.Associations).Save(&comment).Error
+	return err
+}
+
+// Deletes a comment with all replies to it in one transaction.
+// Returns the deleted comments, parents before their replies
+func (d *Database) DeleteComment(comment model.Comment) ([]model.Comment, error) {
+	deleted := []model.Comment{}
+
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		ids := []uint{}
+		level := []model.Comment{comment}
+
+		for len(level) > 0 {
+			parents := []uint{}
+			for _, c := range level {
+				parents = append(parents, c.ID)
+			}
+			deleted = append(deleted, level...)
+			ids = append(ids, parents...)
+
+			level = []model.Comment{}
+			if err := tx.Preload("User").Where("Parent_ID IN ?", parents).Order("ID").Find(&level).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().Where("ID IN ?", ids).Delete(&model.Comment{}).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// Gets attachment by ID
+func (d *Database) GetAttachment(id uint) model.Attachment {
+	attachment := model.Attachment{}
+	err := d.DB.Find(&attachment, id).Error
+
+	if err != nil {
+		return model.Attachment{}
+	}
+
+	return attachment
+}
+
+// Returns the attachments of a task
+func (d *Database) GetAttachments(task model.Task) []model.Attachment {
+	attachments := []model.Attachment{}
+
+	d.DB.Order("ID").Find(&attachments, "Task_ID = ?", task.ID)
+
+	return attachments
+}
+
+// Returns the attachments of all tasks of a project
+func (d *Database) GetProjectAttachments(project model.Project) []model.Attachment {
+	attachments := []model.Attachment{}
+
+	d.DB.Order("ID").Find(&attachments, "Project_ID = ?", project.ID)
+
+	return attachments
+}
+
+// Returns the total size of the attachments of a project in bytes
+func (d *Database) GetProjectAttachmentSize(project model.Project) int64 {
+	var size int64
+
+	d.DB.Model(&model.Attachment{}).Select("COALESCE(SUM(Size), 0)").Where("Project_ID = ?", project.ID).Scan(&size)
+
+	return size
+}
+
+// Counts the attachments stored under a hash
+func (d *Database) CountAttachments(hash string) int64 {
+	var count int64
+
+	d.DB.Model(&model.Attachment{}).Where("Hash = ?", hash).Count(&count)
+
+	return count
+}
+
+// Creates an attachment and returns it with its ID
+func (d *Database) PostAttachment(attachment model.Attachment) (model.Attachment, error) {
+	err := d.DB.Create(&attachment).Error
+	return attachment, err
+}
+
+// Deletes an attachment, the stored file is not removed
+func (d *Database) DeleteAttachment(attachment model.Attachment) error {
+	err := d.DB.Unscoped().Delete(&model.Attachment{}, attachment.ID).Error
+	return err
+}
+
+// Gets checklist item by ID
+func (d *Database) GetChecklistItem(id uint) model.ChecklistItem {
+	item := model.ChecklistItem{}
+	err := d.DB.Find(&item, id).Error
+
+	if err != nil {
+		return model.ChecklistItem{}
+	}
+
+	return item
+}
+
+// Returns the checklist of a task in order
+func (d *Database) GetChecklist(task model.Task) []model.ChecklistItem {
+	items := []model.ChecklistItem{}
+
+	d.DB.Order("Position").Order("ID").Find(&items, "Task_ID = ?", task.ID)
+
+	return items
+}
+
+// Creates a checklist item and returns it with its ID
+func (d *Database) PostChecklistItem(item model.ChecklistItem) (model.ChecklistItem, error) {
+	err := d.DB.Create(&item).Error
+	return item, err
+}
+
+// Updates a checklist item
+func (d *Database) UpdateChecklistItem(item model.ChecklistItem) error {
+	err := d.DB.Save(&item).Error
+	return err
+}
+
+// Updates several checklist items at once, either all or none are saved
+func (d *Database) UpdateChecklist(items []model.ChecklistItem) error {
+	err := d.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range items {
+			if err := tx.Save(&items[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// Deletes a checklist item
+func (d *Database) DeleteChecklistItem(item model.ChecklistItem) error {
+	err := d.DB.Delete(&model.ChecklistItem{}, item.ID).Error
+	return err
+}
+
+// Returns the time entries matching the filter ordered by their start
+func (d *Database) GetTimeEntries(filter model.TimeEntryFilter) []model.TimeEntry {
+	entries := []model.TimeEntry{}
+	query := d.DB.Order("Started_At").Order("ID").Where("Task_ID IN (?)", d.DB.Model(&model.Task{}).Select("ID"))
+
+	if filter.TaskID != 0 {
+		query = query.Where("Task_ID = ?", filter.TaskID)
+	}
+	if filter.ProjectID != 0 {
+		query = query.Where("Project_ID = ?", filter.ProjectID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("User_ID = ?", filter.UserID)
+	}
+	if filter.Running {
+		query = query.Where("Ended_At IS NULL")
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("Started_At >= ?", filter.From.Local())
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("Started_At <= ?", filter.To.Local())
+	}
+
+	query.Find(&entries)
+
+	return entries
+}
+
+// Returned when a user starts a timer while another one is running
+var ErrTimerRunning = errors.New("a timer is already running")
+
+// Creates a time entry and returns it with its ID. The unique index on running timers
+// rejects a second running timer of the user with ErrTimerRunning
+func (d *Database) PostTimeEntry(entry model.TimeEntry) (model.TimeEntry, error) {
+	err := d.DB.Create(&entry).Error
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		err = ErrTimerRunning
+	}
+	return entry, err
+}
+
+// Updates a time entry
+func (d *Database) UpdateTimeEntry(entry model.TimeEntry) error {
+	err := d.DB.Save(&entry).Error
+	return err
+}
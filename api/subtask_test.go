@@ -0,0 +1,96 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for the subtask routes and completing tasks with subtasks
+func TestSubtasks(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	getTask := func(name string) model.Task {
+		workspace := db.GetWorkspace(model.DefaultWorkspaceName)
+		return db.GetTask(db.GetProject(workspace.ID, "release"), name)
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "release"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/release/tasks", `{"name": "deploy"}`)
+
+	t.Run("Create subtasks", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/release/tasks/deploy/subtasks", `{"name": "migrate"}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, alice, http.MethodPost, fmt.Sprintf("/tasks/%d/subtasks", getTask("deploy").ID), `{"name": "announce"}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects/release/tasks/migrate/subtasks", `{"name": "backup"}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		if parent := getTask("migrate").ParentID; parent == nil || *parent != getTask("deploy").ID {
+			t.Errorf("got parent %v, want deploy", parent)
+		}
+	})
+
+	t.Run("Subtasks can not be nested deeper than the maximum", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/release/tasks/backup/subtasks", `{"name": "verify"}`)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("List subtasks with their progress", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodGet, "/projects/release/tasks/deploy/subtasks", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		var subtasks []model.Task
+		json.NewDecoder(response.Body).Decode(&subtasks)
+
+		if len(subtasks) != 2 || subtasks[0].Name != "migrate" || subtasks[0].Subtasks.Total != 1 {
+			t.Errorf("got subtasks %v, want migrate with one subtask and announce", subtasks)
+		}
+	})
+
+	t.Run("Parents with open subtasks can not be completed", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/release/tasks/deploy/complete", "")
+
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+	})
+
+	t.Run("Task JSON reports the progress", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPut, "/projects/release/tasks/announce/complete", "")
+
+		response := serveWithToken(server, alice, http.MethodGet, "/projects/release/tasks/deploy", "")
+
+		var task model.Task
+		json.NewDecoder(response.Body).Decode(&task)
+
+		if task.Subtasks != (model.SubtaskProgress{Total: 2, Done: 1}) {
+			t.Errorf("got progress %v, want 1 of 2", task.Subtasks)
+		}
+	})
+
+	t.Run("Complete a parent together with its subtasks", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, "/projects/release/tasks/deploy/complete?subtasks=complete", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		for _, name := range []string{"deploy", "migrate", "backup"} {
+			if !getTask(name).Done {
+				t.Errorf("task %v was not completed", name)
+			}
+		}
+	})
+
+	t.Run("Deleting a parent deletes its subtasks", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodDelete, "/projects/release/tasks/deploy", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if task := getTask("backup"); task.ID != 0 {
+			t.Error("subtask backup was not deleted")
+		}
+	})
+}
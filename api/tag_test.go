@@ -0,0 +1,141 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+)
+
+// Tests for the tag routes and filtering tasks by tags
+func TestTags(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+
+	taskNames := func(response *httptest.ResponseRecorder) []string {
+		var tasks []model.Task
+		json.NewDecoder(response.Body).Decode(&tasks)
+
+		names := []string{}
+		for _, task := range tasks {
+			names = append(names, task.Name)
+		}
+		return names
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "home"}`)
+	for _, name := range []string{"groceries", "fix sink", "call plumber"} {
+		serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks", `{"name": "`+name+`"}`)
+	}
+
+	t.Run("Create tags", func(t *testing.T) {
+		for _, name := range []string{"errand", "Bug", "waiting-on"} {
+			response := serveWithToken(server, alice, http.MethodPost, "/tags", `{"name": "`+name+`"}`)
+			assertResponseStatus(t, response.Code, http.StatusCreated)
+		}
+
+		response := serveWithToken(server, alice, http.MethodPost, "/tags", `{"name": "bug"}`)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/tags", `{"name": "a,b"}`)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("List tags of the workspace", func(t *testing.T) {
+		response := serveWithToken(server, bob, http.MethodGet, "/tags", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		var tags []model.Tag
+		json.NewDecoder(response.Body).Decode(&tags)
+
+		if len(tags) != 3 || tags[0].Name != "bug" {
+			t.Errorf("got tags %v, want bug, errand and waiting-on", tags)
+		}
+	})
+
+	t.Run("Attach tags to tasks", func(t *testing.T) {
+		attach := map[string][]string{
+			"groceries":    {"errand"},
+			"fix sink":     {"bug", "waiting-on"},
+			"call plumber": {"errand", "waiting-on"},
+		}
+		for task, tags := range attach {
+			for _, tag := range tags {
+				response := serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks/"+task+"/tags", `{"name": "`+tag+`"}`)
+				assertResponseStatus(t, response.Code, http.StatusCreated)
+			}
+		}
+
+		response := serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks/groceries/tags", `{"name": "errand"}`)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects/home/tasks/groceries/tags", `{"name": "unknown"}`)
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("Task JSON includes its tags", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodGet, "/projects/home/tasks/fix sink", "")
+
+		var task model.Task
+		json.NewDecoder(response.Body).Decode(&task)
+
+		if len(task.Tags) != 2 || task.Tags[0].Name != "bug" || task.Tags[1].Name != "waiting-on" {
+			t.Errorf("got tags %v, want bug and waiting-on", task.Tags)
+		}
+	})
+
+	t.Run("Filter tasks by any tag", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodGet, "/projects/home/tasks?tag=bug&tag=errand", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		got := taskNames(response)
+		if len(got) != 3 {
+			t.Errorf("got tasks %v, want all three", got)
+		}
+	})
+
+	t.Run("Filter tasks by all tags", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodGet, "/projects/home/tasks?tag=errand,waiting-on&match=all", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		got := taskNames(response)
+		if len(got) != 1 || got[0] != "call plumber" {
+			t.Errorf("got tasks %v, want call plumber", got)
+		}
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/home/tasks?tag=errand&match=some", "")
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Only the creator can rename or delete a tag", func(t *testing.T) {
+		response := serveWithToken(server, bob, http.MethodPut, "/tags/errand", `{"name": "chore"}`)
+		assertResponseStatus(t, response.Code, http.StatusForbidden)
+
+		response = serveWithToken(server, alice, http.MethodPut, "/tags/errand", `{"name": "chore"}`)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/home/tasks?tag=chore", "")
+		if got := taskNames(response); len(got) != 2 {
+			t.Errorf("got tasks %v, want the two renamed errands", got)
+		}
+	})
+
+	t.Run("Detach a tag", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodDelete, "/projects/home/tasks/fix sink/tags/bug", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodDelete, "/projects/home/tasks/fix sink/tags/bug", "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("Deleting a tag detaches it", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodDelete, "/tags/waiting-on", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/home/tasks?tag=waiting-on", "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+}
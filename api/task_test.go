@@ -15,12 +15,12 @@ import (
 func setupTaskTests() (server *api.TodoStore, store *StubTodoStore) {
 	time := time.Now()
 	store = &StubTodoStore{
-		map[string]bool{
+		Projects: map[string]bool{
 			"homework": false,
 			"cleaning": true,
 			"school":   false,
 		},
-		[]stubTask{{Name: "math",
+		Tasks: []stubTask{{Name: "math",
 			Priority:  "1",
 			Deadline:  &time,
 			Done:      false,
@@ -47,7 +47,7 @@ func TestGetTask(t *testing.T) {
 	server, _ := setupTaskTests()
 
 	t.Run("Get task Math from project homework", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodGet, "/projects/homework/tasks/math", nil)
+		request := newAuthenticatedRequest(http.MethodGet, "/projects/homework/tasks/math", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -60,7 +60,7 @@ func TestGetTask(t *testing.T) {
 	})
 
 	t.Run("Try to get task from wrong project", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodGet, "/projects/homework/tasks/kitchen", nil)
+		request := newAuthenticatedRequest(http.MethodGet, "/projects/homework/tasks/kitchen", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -73,7 +73,7 @@ func TestGetTask(t *testing.T) {
 	})
 
 	t.Run("Try to get nonexisting Task", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodGet, "/projects/homework/tasks/biology", nil)
+		request := newAuthenticatedRequest(http.MethodGet, "/projects/homework/tasks/biology", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -92,7 +92,7 @@ func TestPostTask(t *testing.T) {
 
 	t.Run("Create a new task for project homework", func(t *testing.T) {
 		requestBody := makeNewPostTaskBody(t, "biology", "homework")
-		request, _ := http.NewRequest(http.MethodPost, "/projects/homework/tasks", requestBody)
+		request := newAuthenticatedRequest(http.MethodPost, "/projects/homework/tasks", requestBody)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -103,7 +103,7 @@ func TestPostTask(t *testing.T) {
 
 	t.Run("Try to create an already existing task", func(t *testing.T) {
 		requestBody := makeNewPostTaskBody(t, "math", "homework")
-		request, _ := http.NewRequest(http.MethodPost, "/projects/homework/tasks", requestBody)
+		request := newAuthenticatedRequest(http.MethodPost, "/projects/homework/tasks", requestBody)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -113,7 +113,7 @@ func TestPostTask(t *testing.T) {
 
 	t.Run("Try to create a task for a nonexisting project", func(t *testing.T) {
 		requestBody := makeNewPostTaskBody(t, "biology", "homework2")
-		request, _ := http.NewRequest(http.MethodPost, "/projects/homework2/tasks", requestBody)
+		request := newAuthenticatedRequest(http.MethodPost, "/projects/homework2/tasks", requestBody)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -126,7 +126,7 @@ func TestGetAllTasksOfAProject(t *testing.T) {
 	server, store := setupTaskTests()
 
 	t.Run("Get all task from project homework", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodGet, "/projects/homework/tasks", nil)
+		request := newAuthenticatedRequest(http.MethodGet, "/projects/homework/tasks", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -140,7 +140,7 @@ func TestGetAllTasksOfAProject(t *testing.T) {
 	})
 
 	t.Run("Try to get task from a project without tasks", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodGet, "/projects/school/tasks", nil)
+		request := newAuthenticatedRequest(http.MethodGet, "/projects/school/tasks", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -155,7 +155,7 @@ func TestDeleteTask(t *testing.T) {
 	server, store := setupTaskTests()
 
 	t.Run("Delete task math from project homework", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodDelete, "/projects/homework/tasks/math", nil)
+		request := newAuthenticatedRequest(http.MethodDelete, "/projects/homework/tasks/math", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -165,7 +165,7 @@ func TestDeleteTask(t *testing.T) {
 	})
 
 	t.Run("Try to delete nonexisting task", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodDelete, "/projects/homework/tasks/science", nil)
+		request := newAuthenticatedRequest(http.MethodDelete, "/projects/homework/tasks/science", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -180,7 +180,7 @@ func TestUpdateTask(t *testing.T) {
 
 	t.Run("Update task math from project homework", func(t *testing.T) {
 		requestBody := makeNewPostTaskBody(t, "mathhomework", "homework")
-		request, _ := http.NewRequest(http.MethodPut, "/projects/homework/tasks/math", requestBody)
+		request := newAuthenticatedRequest(http.MethodPut, "/projects/homework/tasks/math", requestBody)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -191,7 +191,7 @@ func TestUpdateTask(t *testing.T) {
 
 	t.Run("Try to update a nonexistent task", func(t *testing.T) {
 		requestBody := makeNewPostTaskBody(t, "mathhomework", "homework")
-		request, _ := http.NewRequest(http.MethodPut, "/projects/homework/tasks/kitchen", requestBody)
+		request := newAuthenticatedRequest(http.MethodPut, "/projects/homework/tasks/kitchen", requestBody)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -205,7 +205,7 @@ func TestCompleteTask(t *testing.T) {
 	server, store := setupTaskTests()
 
 	t.Run("Compelete task physics from project homework", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodDelete, "/projects/homework/tasks/physics/complete", nil)
+		request := newAuthenticatedRequest(http.MethodDelete, "/projects/homework/tasks/physics/complete", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -215,7 +215,7 @@ func TestCompleteTask(t *testing.T) {
 	})
 
 	t.Run("Try to reopen nonexisting task biology", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodDelete, "/projects/homework/tasks/biology/complete", nil)
+		request := newAuthenticatedRequest(http.MethodDelete, "/projects/homework/tasks/biology/complete", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -229,7 +229,7 @@ func TestReopeningTask(t *testing.T) {
 	server, store := setupTaskTests()
 
 	t.Run("Reopen task math from project homework", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodPut, "/projects/homework/tasks/math/complete", nil)
+		request := newAuthenticatedRequest(http.MethodPut, "/projects/homework/tasks/math/complete", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
@@ -239,7 +239,7 @@ func TestReopeningTask(t *testing.T) {
 	})
 
 	t.Run("Try to compelete nonexisting task biology", func(t *testing.T) {
-		request, _ := http.NewRequest(http.MethodPut, "/projects/homework/tasks/biology/complete", nil)
+		request := newAuthenticatedRequest(http.MethodPut, "/projects/homework/tasks/biology/complete", nil)
 		response := httptest.NewRecorder()
 
 		server.Router.ServeHTTP(response, request)
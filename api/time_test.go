@@ -0,0 +1,115 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Tests for timers, time entries and the time reports of tasks and projects
+func TestTimeTracking(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+
+	getReport := func(url string) model.TimeReport {
+		var report model.TimeReport
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, url, "").Body).Decode(&report)
+		return report
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "client"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/client/tasks", `{"name": "design"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/client/tasks", `{"name": "build"}`)
+
+	design := "/projects/client/tasks/design"
+	build := "/projects/client/tasks/build"
+
+	t.Run("Only one timer runs at a time", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, design+"/timer/start", "")
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, alice, http.MethodPost, build+"/timer/start", "")
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+
+		response = serveWithToken(server, alice, http.MethodPost, build+"/timer/stop", "")
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+
+		response = serveWithToken(server, alice, http.MethodPost, design+"/timer/stop", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodPost, build+"/timer/start", "")
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+	})
+
+	t.Run("Add time entries by hand", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPost, design+"/time", `{"start": "2030-03-01T09:00:00Z", "end": "2030-03-01T10:30:00Z", "note": "mockups"}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, alice, http.MethodPost, design+"/time", `{"start": "2030-03-02T09:00:00Z", "end": "2030-03-02T08:00:00Z"}`)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = serveWithToken(server, alice, http.MethodPost, design+"/time", `{"start": "2030-03-02T09:00:00Z"}`)
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		report := getReport(design + "/time")
+		if len(report.Entries) != 2 || report.Entries[1].Note != "mockups" || report.Seconds < 5400 {
+			t.Errorf("got report %v, want the timer and 90 minutes of mockups", report)
+		}
+	})
+
+	t.Run("Report the time of a project over a range", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, build+"/time", `{"start": "2030-04-01T09:00:00Z", "end": "2030-04-01T11:00:00Z"}`)
+
+		report := getReport("/projects/client/time?from=2030-01-01T00:00:00Z&to=2030-03-31T23:59:59Z")
+		if report.Seconds != 5400 || len(report.Tasks) != 1 || report.Tasks[0].Name != "design" {
+			t.Errorf("got report %v, want 90 minutes on design", report)
+		}
+
+		report = getReport("/projects/client/time?from=2030-01-01T00:00:00Z")
+		if report.Seconds != 5400+7200 || len(report.Tasks) != 2 {
+			t.Errorf("got report %v, want the time of design and build", report)
+		}
+
+		response := serveWithToken(server, alice, http.MethodGet, "/projects/client/time?from=yesterday", "")
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Completing a task stops its timer", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodPut, build+"/complete", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		for _, entry := range getReport(build + "/time").Entries {
+			if entry.End == nil {
+				t.Error("timer of the completed task is still running")
+			}
+		}
+
+		response = serveWithToken(server, alice, http.MethodPost, build+"/timer/start", "")
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+
+		response = serveWithToken(server, alice, http.MethodPost, design+"/timer/start", "")
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+	})
+	t.Run("The store starts only one timer per user", func(t *testing.T) {
+		running := db.GetTimeEntries(model.TimeEntryFilter{Running: true})
+		if len(running) != 1 {
+			t.Fatalf("got running timers %v, want the timer of design", running)
+		}
+
+		// Requests that both passed the check of the handler
+		second := model.TimeEntry{TaskID: running[0].TaskID, ProjectID: running[0].ProjectID, UserID: running[0].UserID, Start: time.Now()}
+		if _, err := db.PostTimeEntry(second); !errors.Is(err, store.ErrTimerRunning) {
+			t.Errorf("got error %v, want %v", err, store.ErrTimerRunning)
+		}
+
+		second.Stop(time.Now())
+		if _, err := db.PostTimeEntry(second); err != nil {
+			t.Errorf("could not add a stopped entry: %v", err)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/handler"
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+)
+
+// Tests for deleting projects and tasks into the trash, restoring and purging them
+func TestTrash(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+
+	getTrash := func(token string) []model.TrashItem {
+		var items []model.TrashItem
+		json.NewDecoder(serveWithToken(server, token, http.MethodGet, "/trash", "").Body).Decode(&items)
+		return items
+	}
+
+	project := func(name string) model.Project {
+		return db.GetProject(db.GetWorkspace(model.DefaultWorkspaceName).ID, name)
+	}
+
+	serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "trip"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/trip/members", `{"user": "bob", "role": "editor"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/trip/tasks", `{"name": "tickets"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/trip/tasks/tickets/subtasks", `{"name": "compare prices"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/trip/tasks/tickets/comments", `{"body": "Night train?"}`)
+	serveWithToken(server, alice, http.MethodPost, "/projects/trip/tasks", `{"name": "hotel"}`)
+
+	tickets := db.GetTask(project("trip"), "tickets")
+
+	t.Run("Deleted tasks go to the trash", func(t *testing.T) {
+		response := serveWithToken(server, alice, http.MethodDelete, "/projects/trip/tasks/tickets", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/trip/tasks/compare prices", "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+
+		items := getTrash(alice)
+		if len(items) != 1 || items[0].Name != "tickets" || items[0].Project != "trip" {
+			t.Errorf("got trash %v, want tickets without its subtask", items)
+		}
+
+		if items := getTrash(bob); len(items) != 0 {
+			t.Errorf("got trash %v, want nothing for an editor", items)
+		}
+	})
+
+	t.Run("Restore a task with its subtasks and comments", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodPost, "/projects/trip/tasks", `{"name": "tickets"}`)
+
+		url := fmt.Sprintf("/trash/tasks/%d/restore", tickets.ID)
+
+		response := serveWithToken(server, alice, http.MethodPost, url, "")
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+
+		response = serveWithToken(server, bob, http.MethodPost, url, "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+
+		serveWithToken(server, alice, http.MethodDelete, "/projects/trip/tasks/tickets", "")
+		serveWithToken(server, alice, http.MethodDelete, fmt.Sprintf("/trash/tasks/%d", db.GetTrash(model.TrashFilter{})[0].ID), "")
+
+		response = serveWithToken(server, alice, http.MethodPost, url, "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/trip/tasks/compare prices", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		var comments []model.Comment
+		json.NewDecoder(serveWithToken(server, alice, http.MethodGet, "/projects/trip/tasks/tickets/comments", "").Body).Decode(&comments)
+		if len(comments) != 1 {
+			t.Errorf("got comments %v, want the comment of tickets", comments)
+		}
+	})
+
+	t.Run("Names of deleted projects can be reused", func(t *testing.T) {
+		trip := project("trip")
+
+		response := serveWithToken(server, alice, http.MethodDelete, "/projects/trip", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodPost, "/projects", `{"name": "trip"}`)
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		url := fmt.Sprintf("/trash/projects/%d/restore", trip.ID)
+
+		response = serveWithToken(server, alice, http.MethodPost, url, "")
+		assertResponseStatus(t, response.Code, http.StatusConflict)
+
+		reused := project("trip")
+		serveWithToken(server, alice, http.MethodDelete, "/projects/trip", "")
+
+		response = serveWithToken(server, alice, http.MethodDelete, fmt.Sprintf("/trash/projects/%d", reused.ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		items := getTrash(alice)
+		if len(items) != 1 || items[0].ID != trip.ID {
+			t.Errorf("got trash %v, want the first project only", items)
+		}
+	})
+
+	t.Run("Restore a project with its tasks", func(t *testing.T) {
+		trip := db.GetTrashedProject(getTrash(alice)[0].ID)
+
+		response := serveWithToken(server, alice, http.MethodPost, fmt.Sprintf("/trash/projects/%d/restore", trip.ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, alice, http.MethodGet, "/projects/trip/tasks/hotel", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		if got := db.GetAllProjectTasks(project("trip"), model.TaskFilter{}); len(got) != 3 {
+			t.Errorf("got %d tasks, want tickets, compare prices and hotel", len(got))
+		}
+	})
+
+	t.Run("Restored tasks keep a unique position", func(t *testing.T) {
+		hotel := db.GetTask(project("trip"), "hotel")
+		serveWithToken(server, alice, http.MethodDelete, "/projects/trip/tasks/hotel", "")
+		serveWithToken(server, alice, http.MethodPost, "/projects/trip/tasks", `{"name": "car"}`)
+
+		response := serveWithToken(server, alice, http.MethodPost, fmt.Sprintf("/trash/tasks/%d/restore", hotel.ID), "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		positions := map[string]string{}
+		for _, task := range db.GetAllProjectTasks(project("trip"), model.TaskFilter{}) {
+			if other, ok := positions[task.Position]; ok {
+				t.Errorf("got position %q for %s and %s, want unique positions", task.Position, other, task.Name)
+			}
+			positions[task.Position] = task.Name
+		}
+	})
+
+	t.Run("Items past the retention are purged", func(t *testing.T) {
+		serveWithToken(server, alice, http.MethodDelete, "/projects/trip/tasks/hotel", "")
+
+		handler.PurgeExpiredTrash(db, nil, time.Now().Add(-time.Hour))
+		if items := getTrash(alice); len(items) != 1 {
+			t.Errorf("got trash %v, want hotel kept", items)
+		}
+
+		handler.PurgeExpiredTrash(db, nil, time.Now().Add(time.Hour))
+		if items := getTrash(alice); len(items) != 0 {
+			t.Errorf("got trash %v, want it empty", items)
+		}
+	})
+}
+
+// Tests that the unique index on project names of older databases is replaced by one ignoring the trash
+func TestProjectNameIndexMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db := store.NewDatabaseConnection(path)
+
+	err := db.DB.Exec("CREATE UNIQUE INDEX idx_workspace_project_name ON projects (name, workspace_id)").Error
+	assertError(t, "create legacy index", err)
+
+	db = store.NewDatabaseConnection(path)
+
+	project := model.Project{Name: "reused", WorkspaceID: 1}
+	assertError(t, "create project", db.PostProject(project))
+	assertError(t, "delete project", db.DeleteProject(db.GetProject(1, "reused")))
+	assertError(t, "create project again", db.PostProject(project))
+}
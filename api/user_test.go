@@ -0,0 +1,198 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	api "github.com/mpfen/Go-Todo-REST-API/api"
+)
+
+func setUpUserTests() (server *api.TodoStore, store *StubTodoStore) {
+	store = &StubTodoStore{
+		Projects: map[string]bool{
+			"homework": false,
+		},
+	}
+
+	server = api.NewTodoStore(store)
+	return server, store
+}
+
+// Test for route POST /users
+func TestPostUser(t *testing.T) {
+	server, store := setUpUserTests()
+
+	t.Run("Register user bob", func(t *testing.T) {
+		requestBody := makeNewCredentialsBody(t, "bob", "secret password")
+		request, _ := http.NewRequest(http.MethodPost, "/users", requestBody)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		user := store.Users["bob"]
+		if user.Name != "bob" || !user.CheckPassword("secret password") {
+			t.Errorf("user was not created with a hashed password")
+		}
+	})
+
+	t.Run("Try to register an existing user", func(t *testing.T) {
+		requestBody := makeNewCredentialsBody(t, "bob", "another password")
+		request, _ := http.NewRequest(http.MethodPost, "/users", requestBody)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Try to register with a short password", func(t *testing.T) {
+		requestBody := makeNewCredentialsBody(t, "carol", "short")
+		request, _ := http.NewRequest(http.MethodPost, "/users", requestBody)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Try to register with a password bcrypt can not hash", func(t *testing.T) {
+		requestBody := makeNewCredentialsBody(t, "carol", strings.Repeat("long", 19))
+		request, _ := http.NewRequest(http.MethodPost, "/users", requestBody)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+// Test for routes POST /login and POST /logout
+func TestLogin(t *testing.T) {
+	server, _ := setUpUserTests()
+
+	requestBody := makeNewCredentialsBody(t, "bob", "secret password")
+	request, _ := http.NewRequest(http.MethodPost, "/users", requestBody)
+	server.Router.ServeHTTP(httptest.NewRecorder(), request)
+
+	var token string
+
+	t.Run("Login with correct password", func(t *testing.T) {
+		requestBody := makeNewCredentialsBody(t, "bob", "secret password")
+		request, _ := http.NewRequest(http.MethodPost, "/login", requestBody)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		var body map[string]string
+		json.NewDecoder(response.Body).Decode(&body)
+		token = body["token"]
+
+		if token == "" {
+			t.Errorf("no token returned")
+		}
+	})
+
+	t.Run("Login with wrong password", func(t *testing.T) {
+		requestBody := makeNewCredentialsBody(t, "bob", "wrong password")
+		request, _ := http.NewRequest(http.MethodPost, "/login", requestBody)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Issued token authenticates requests", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/me", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("Logout revokes the token", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodPost, "/logout", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		request, _ = http.NewRequest(http.MethodGet, "/me", nil)
+		request.Header.Set("Authorization", "Bearer "+token)
+		response = httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+		assertResponseStatus(t, response.Code, http.StatusUnauthorized)
+	})
+}
+
+// Test that project routes reject unauthenticated requests
+func TestAuthentication(t *testing.T) {
+	server, _ := setUpUserTests()
+
+	t.Run("Request without token", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/projects", nil)
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Request with invalid token", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/projects/homework", nil)
+		request.Header.Set("Authorization", "Bearer invalid")
+		response := httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusUnauthorized)
+	})
+
+	t.Run("Other users do not see the projects", func(t *testing.T) {
+		requestBody := makeNewCredentialsBody(t, "mallory", "secret password")
+		request, _ := http.NewRequest(http.MethodPost, "/users", requestBody)
+		server.Router.ServeHTTP(httptest.NewRecorder(), request)
+
+		requestBody = makeNewCredentialsBody(t, "mallory", "secret password")
+		request, _ = http.NewRequest(http.MethodPost, "/login", requestBody)
+		response := httptest.NewRecorder()
+		server.Router.ServeHTTP(response, request)
+
+		var body map[string]string
+		json.NewDecoder(response.Body).Decode(&body)
+
+		request, _ = http.NewRequest(http.MethodGet, "/projects/homework", nil)
+		request.Header.Set("Authorization", "Bearer "+body["token"])
+		response = httptest.NewRecorder()
+
+		server.Router.ServeHTTP(response, request)
+
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
+// makes a new json request body for POST /users and POST /login
+func makeNewCredentialsBody(t *testing.T, name, password string) *bytes.Buffer {
+	requestBody, err := json.Marshal(map[string]string{
+		"name":     name,
+		"password": password,
+	})
+
+	if err != nil {
+		t.Errorf("Failed to make requestBody: %s", err)
+	}
+
+	return bytes.NewBuffer(requestBody)
+}
@@ -0,0 +1,172 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/mpfen/Go-Todo-REST-API/api/model"
+	"github.com/mpfen/Go-Todo-REST-API/api/store"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Tests for the /workspaces routes and the isolation of projects between workspaces
+func TestWorkspaces(t *testing.T) {
+	server, db := setUpDatabaseServer(t)
+
+	tokens := map[string]string{}
+	for _, name := range []string{"alice", "bob", "carol"} {
+		tokens[name] = createTestUser(t, db, name)
+	}
+
+	// alice owns team-a with bob as viewer, carol owns team-b
+	assertResponseStatus(t, serveWithToken(server, tokens["alice"], http.MethodPost, "/workspaces", makeNewWorkspaceBody(t, "team-a").String()).Code, http.StatusCreated)
+	assertResponseStatus(t, serveWithToken(server, tokens["carol"], http.MethodPost, "/workspaces", makeNewWorkspaceBody(t, "team-b").String()).Code, http.StatusCreated)
+	assertResponseStatus(t, serveWithToken(server, tokens["alice"], http.MethodPost, "/workspaces/team-a/members", makeNewMembershipBody(t, "bob", model.RoleViewer).String()).Code, http.StatusCreated)
+
+	t.Run("Workspace names are unique", func(t *testing.T) {
+		response := serveWithToken(server, tokens["carol"], http.MethodPost, "/workspaces", makeNewWorkspaceBody(t, "team-a").String())
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Project names are unique per workspace", func(t *testing.T) {
+		for _, url := range []string{"/workspaces/team-a/projects", "/workspaces/team-b/projects", "/projects"} {
+			user := "alice"
+			if url == "/workspaces/team-b/projects" {
+				user = "carol"
+			}
+
+			response := serveWithToken(server, tokens[user], http.MethodPost, url, makeNewPostProjectBody(t, "backlog").String())
+			assertResponseStatus(t, response.Code, http.StatusCreated)
+		}
+
+		response := serveWithToken(server, tokens["alice"], http.MethodPost, "/workspaces/team-a/projects", makeNewPostProjectBody(t, "backlog").String())
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("Tasks stay in their workspace", func(t *testing.T) {
+		response := serveWithToken(server, tokens["carol"], http.MethodPost, "/workspaces/team-b/projects/backlog/tasks", makeNewPostTaskBody(t, "hiring", "backlog").String())
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, tokens["alice"], http.MethodGet, "/workspaces/team-a/projects/backlog/tasks/hiring", "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+
+		response = serveWithToken(server, tokens["carol"], http.MethodGet, "/workspaces/team-b/projects/backlog/tasks/hiring", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("Non members do not see a workspace", func(t *testing.T) {
+		for _, url := range []string{"/workspaces/team-b", "/workspaces/team-b/projects", "/workspaces/team-b/projects/backlog"} {
+			response := serveWithToken(server, tokens["alice"], http.MethodGet, url, "")
+			assertResponseStatus(t, response.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("Viewers can not create projects", func(t *testing.T) {
+		response := serveWithToken(server, tokens["bob"], http.MethodPost, "/workspaces/team-a/projects", makeNewPostProjectBody(t, "roadmap").String())
+
+		assertResponseStatus(t, response.Code, http.StatusForbidden)
+	})
+
+	t.Run("Projects can only be shared with workspace members", func(t *testing.T) {
+		response := serveWithToken(server, tokens["alice"], http.MethodPost, "/workspaces/team-a/projects/backlog/members", makeNewMembershipBody(t, "carol", model.RoleViewer).String())
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+
+		response = serveWithToken(server, tokens["alice"], http.MethodPost, "/workspaces/team-a/projects/backlog/members", makeNewMembershipBody(t, "bob", model.RoleViewer).String())
+		assertResponseStatus(t, response.Code, http.StatusCreated)
+
+		response = serveWithToken(server, tokens["bob"], http.MethodGet, "/workspaces/team-a/projects/backlog", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("Moving projects requires the editor role in the workspace", func(t *testing.T) {
+		serveWithToken(server, tokens["alice"], http.MethodPost, "/workspaces/team-a/projects", makeNewPostProjectBody(t, "roadmap").String())
+		response := serveWithToken(server, tokens["alice"], http.MethodPut, "/workspaces/team-a/projects/backlog/members/bob", makeNewMembershipBody(t, "bob", model.RoleEditor).String())
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		roadmap := db.GetProject(db.GetWorkspace("team-a").ID, "roadmap")
+		body := fmt.Sprintf(`{"after": %d}`, roadmap.ID)
+
+		response = serveWithToken(server, tokens["bob"], http.MethodPut, "/workspaces/team-a/projects/backlog/position", body)
+		assertResponseStatus(t, response.Code, http.StatusForbidden)
+
+		response = serveWithToken(server, tokens["alice"], http.MethodPut, "/workspaces/team-a/projects/backlog/position", body)
+		assertResponseStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("List the workspaces of a user", func(t *testing.T) {
+		response := serveWithToken(server, tokens["bob"], http.MethodGet, "/workspaces", "")
+
+		var workspaces []model.Workspace
+		json.NewDecoder(response.Body).Decode(&workspaces)
+
+		if len(workspaces) != 2 || workspaces[0].Name != model.DefaultWorkspaceName || workspaces[1].Name != "team-a" {
+			t.Errorf("got workspaces %v, want default and team-a", workspaces)
+		}
+	})
+
+	t.Run("Removed members lose access", func(t *testing.T) {
+		response := serveWithToken(server, tokens["bob"], http.MethodDelete, "/workspaces/team-a/members/bob", "")
+		assertResponseStatus(t, response.Code, http.StatusOK)
+
+		response = serveWithToken(server, tokens["bob"], http.MethodGet, "/workspaces/team-a/projects/backlog", "")
+		assertResponseStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("The last owner can not leave", func(t *testing.T) {
+		response := serveWithToken(server, tokens["alice"], http.MethodDelete, "/workspaces/team-a/members/alice", "")
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("The default workspace has no members", func(t *testing.T) {
+		response := serveWithToken(server, tokens["alice"], http.MethodPost, "/workspaces/default/members", makeNewMembershipBody(t, "bob", model.RoleViewer).String())
+
+		assertResponseStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+// Tests that projects of a database created before workspaces move into the default workspace
+func TestWorkspaceMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+
+	legacy, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	assertError(t, "open legacy database", err)
+
+	err = legacy.Exec("CREATE TABLE `projects` (`id` integer,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime," +
+		"`name` text UNIQUE,`archived` numeric,`user_id` integer,PRIMARY KEY (`id`))").Error
+	assertError(t, "create legacy projects table", err)
+
+	err = legacy.Exec("INSERT INTO `projects` (`name`, `archived`, `user_id`) VALUES ('backlog', false, 1)").Error
+	assertError(t, "insert legacy project", err)
+
+	sqlDB, _ := legacy.DB()
+	sqlDB.Close()
+
+	db := store.NewDatabaseConnection(path)
+	workspace := db.GetWorkspace(model.DefaultWorkspaceName)
+
+	if project := db.GetProject(workspace.ID, "backlog"); project.ID != 1 {
+		t.Errorf("legacy project is not in the default workspace: %v", project)
+	}
+
+	err = db.PostProject(model.Project{Name: "backlog", UserID: 1, WorkspaceID: workspace.ID + 1})
+	assertError(t, "create project with the same name in another workspace", err)
+}
+
+func makeNewWorkspaceBody(t *testing.T, name string) *bytes.Buffer {
+	requestBody, err := json.Marshal(map[string]string{
+		"name": name,
+	})
+
+	if err != nil {
+		t.Errorf("Failed to make requestBody: %s", err)
+	}
+
+	return bytes.NewBuffer(requestBody)
+}
@@ -3,9 +3,11 @@ module github.com/mpfen/Go-Todo-REST-API
 go 1.16
 
 require (
-	github.com/gorilla/mux v1.8.0 // indirect
-	github.com/mattn/go-sqlite3 v1.14.7 // indirect
+	github.com/gorilla/mux v1.8.0
+	github.com/mattn/go-sqlite3 v1.14.7
+	github.com/yuin/goldmark v1.3.5
+	golang.org/x/crypto v0.0.0-20210513164829-c07d793c2f9a
 	golang.org/x/tools v0.1.2 // indirect
-	gorm.io/driver/sqlite v1.1.4 // indirect
-	gorm.io/gorm v1.21.10 // indirect
+	gorm.io/driver/sqlite v1.1.4
+	gorm.io/gorm v1.21.10
 )
@@ -3,8 +3,14 @@ package main
 import (
 	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/mpfen/Go-Todo-REST-API/api"
+	"github.com/mpfen/Go-Todo-REST-API/api/blob"
+	"github.com/mpfen/Go-Todo-REST-API/api/handler"
+	"github.com/mpfen/Go-Todo-REST-API/api/oidc"
 	"github.com/mpfen/Go-Todo-REST-API/api/store"
 )
 
@@ -12,7 +18,50 @@ func main() {
 	db := store.NewDatabaseConnection("database.db")
 	server := api.NewTodoStore(db)
 
-	err := http.ListenAndServe(":5000", server.Router)
+	// Accept JWTs of an identity provider if a JWKS file or URL is configured
+	if jwks := os.Getenv("OIDC_JWKS"); jwks != "" {
+		issuer, audience := os.Getenv("OIDC_ISSUER"), os.Getenv("OIDC_AUDIENCE")
+
+		// Without them tokens of any issuer or audience signed by the keys would be accepted
+		if issuer == "" || audience == "" {
+			log.Fatalf("OIDC_JWKS requires OIDC_ISSUER and OIDC_AUDIENCE")
+		}
+
+		validator, err := oidc.NewValidator(issuer, audience, jwks)
+
+		if err != nil {
+			log.Fatalf("could not load JWKS %v", err)
+		}
+		server.OIDC = validator
+	}
+
+	// Store attachments in ATTACHMENT_DIR or ./attachments
+	attachmentDir := os.Getenv("ATTACHMENT_DIR")
+	if attachmentDir == "" {
+		attachmentDir = "attachments"
+	}
+
+	files, err := blob.NewStore(attachmentDir)
+
+	if err != nil {
+		log.Fatalf("could not create attachment directory %v", err)
+	}
+	server.Attachments = handler.NewAttachmentStorage(files)
+
+	// Purge deleted projects and tasks after TRASH_RETENTION_DAYS or 30 days, 0 keeps them until purged by hand
+	retentionDays := 30
+	if days := os.Getenv("TRASH_RETENTION_DAYS"); days != "" {
+		retentionDays, err = strconv.Atoi(days)
+
+		if err != nil || retentionDays < 0 {
+			log.Fatalf("invalid TRASH_RETENTION_DAYS %v", days)
+		}
+	}
+	if retentionDays > 0 {
+		go handler.AutoPurgeTrash(db, server.Attachments, time.Duration(retentionDays)*24*time.Hour, time.Hour)
+	}
+
+	err = http.ListenAndServe(":5000", server.Router)
 
 	if err != nil {
 		log.Fatalf("could not listen on port 5000 %v", err)